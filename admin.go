@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// maxRestoreSize caps the size of an uploaded dump.
+const maxRestoreSize = 50 << 20
+
+type adminView struct {
+	Path    string
+	Title   string
+	Error   string
+	Success string
+	Backups *scheduledBackups
+}
+
+// scheduledBackups lists the files in the backup directory, newest first.
+type scheduledBackups struct {
+	Dir   string
+	Files []string
+}
+
+func newAdminView() adminView {
+	return adminView{
+		Path:  "/admin",
+		Title: "Admin",
+	}
+}
+
+func (v adminView) withError(msg string) adminView {
+	v.Error = msg
+	return v
+}
+
+func (v adminView) withSuccess(msg string) adminView {
+	v.Success = msg
+	return v
+}
+
+// withBackups lists the scheduled backups if they are enabled.
+func (v adminView) withBackups() adminView {
+	cfg, ok := backupSettings()
+	if !ok {
+		return v
+	}
+	backups := &scheduledBackups{Dir: cfg.Dir}
+	for _, prefix := range []string{dailyPrefix, weeklyPrefix} {
+		names, err := listBackups(cfg.Dir, prefix)
+		if err != nil {
+			slog.Error("could not list backups", "error", err)
+		}
+		backups.Files = append(backups.Files, names...)
+	}
+	v.Backups = backups
+	return v
+}
+
+func (a *App) renderAdmin(w http.ResponseWriter, view adminView) {
+	view = view.withBackups()
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/admin.html")
+}
+
+func (a *App) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := requireAuth(w, r); !ok {
+		return
+	}
+	a.renderAdmin(w, newAdminView())
+}
+
+func (a *App) handleDump(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := requireAuth(w, r); !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	dump, err := a.store.Dump(r.Context(), now)
+	if err != nil {
+		http.Error(w, "failed to dump database", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="hest-`+now.Format(dateLayout)+`.json"`)
+	if err := writeDump(w, dump); err != nil {
+		slog.Error("could not write dump", "error", err)
+	}
+}
+
+func (a *App) handleRestore(w http.ResponseWriter, r *http.Request) {
+	username, ok := ensureAuthAndForm(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseMultipartForm(maxRestoreSize); err != nil {
+		a.renderAdmin(w, newAdminView().withError("Vælg en backupfil."))
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		a.renderAdmin(w, newAdminView().withError("Vælg en backupfil."))
+		return
+	}
+	defer file.Close()
+
+	dump, err := readDump(io.LimitReader(file, maxRestoreSize))
+	if err != nil {
+		a.renderAdmin(w, newAdminView().withError("Kunne ikke læse backup: "+err.Error()))
+		return
+	}
+
+	if err := a.store.Restore(dump); err != nil {
+		msg := "Gendannelsen fejlede, intet blev gemt: " + err.Error()
+		if errors.Is(err, db.ErrNotEmpty) {
+			msg = "Databasen er ikke tom. Der kan kun gendannes til en tom database."
+		}
+		a.renderAdmin(w, newAdminView().withError(msg))
+		return
+	}
+
+	slog.Info("restored dump", "user", username, "players", len(dump.Players), "games", len(dump.Games))
+	a.renderAdmin(w, newAdminView().withSuccess(
+		fmt.Sprintf("Gendannet %d spillere og %d kampe.", len(dump.Players), len(dump.Games))))
+}
+
+func writeDump(w io.Writer, dump db.Dump) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump)
+}
+
+func readDump(r io.Reader) (db.Dump, error) {
+	var dump db.Dump
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&dump)
+	return dump, err
+}
+
+// runDump is the dump command. It writes the dump to the given file, or to
+// stdout if none is given.
+func runDump(store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hest dump [file.json]")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dump, err := store.Dump(context.Background(), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return writeDump(os.Stdout, dump)
+	}
+
+	file, err := os.Create(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := writeDump(file, dump); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runRestore is the restore command. It restores a dump into an empty
+// database.
+func runRestore(store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hest restore file.json")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected a single dump file")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dump, err := readDump(file)
+	if err != nil {
+		return err
+	}
+	if err := store.Restore(dump); err != nil {
+		return err
+	}
+	fmt.Printf("restored %d players and %d games\n", len(dump.Players), len(dump.Games))
+	return nil
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// The API types below are the stable JSON shapes of the /api/v1 endpoints.
+// They are kept separate from the db types so the database can change without
+// breaking API clients.
+
+type apiPlayerRef struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Emoji string `json:"emoji"`
+}
+
+type apiPlayer struct {
+	ID      int     `json:"id"`
+	Name    string  `json:"name"`
+	Emoji   string  `json:"emoji"`
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"`
+	Seconds int     `json:"seconds"`
+	Points  int     `json:"points"`
+	PPG     float64 `json:"ppg"`
+}
+
+type apiLeaderboardEntry struct {
+	Rank int `json:"rank"`
+	apiPlayer
+}
+
+type apiGame struct {
+	ID           int            `json:"id"`
+	PlayedAt     time.Time      `json:"played_at"`
+	Winner       apiPlayerRef   `json:"winner"`
+	Second       apiPlayerRef   `json:"second"`
+	Participants []apiPlayerRef `json:"participants"`
+	CreatedBy    string         `json:"created_by"`
+	SessionID    int            `json:"session_id,omitempty"`
+}
+
+type apiGameHistoryEntry struct {
+	PlayedAt     time.Time `json:"played_at"`
+	PointsEarned int       `json:"points_earned"`
+	TotalPoints  int       `json:"total_points"`
+	GamesPlayed  int       `json:"games_played"`
+	PPG          float64   `json:"ppg"`
+}
+
+type apiRankHistoryEntry struct {
+	PlayedAt time.Time `json:"played_at"`
+	Rank     int       `json:"rank"`
+}
+
+type apiPlayerDetail struct {
+	Rank int `json:"rank"`
+	apiPlayer
+	GameHistory []apiGameHistoryEntry `json:"game_history"`
+	RankHistory []apiRankHistoryEntry `json:"rank_history"`
+}
+
+type apiH2H struct {
+	Player1       apiPlayer `json:"player1"`
+	Player2       apiPlayer `json:"player2"`
+	SharedGames   int       `json:"shared_games"`
+	Player1Stats  apiPlayer `json:"player1_stats"`
+	Player2Stats  apiPlayer `json:"player2_stats"`
+	Player1Ahead  int       `json:"player1_ahead"`
+	Player2Ahead  int       `json:"player2_ahead"`
+	BothOffPodium int       `json:"both_off_podium"`
+	Games         []apiGame `json:"games"`
+}
+
+type apiFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type apiError struct {
+	Error  string          `json:"error"`
+	Fields []apiFieldError `json:"fields,omitempty"`
+}
+
+func toAPIPlayer(p db.Player) apiPlayer {
+	return apiPlayer{
+		ID:      p.ID,
+		Name:    p.Name,
+		Emoji:   p.Emoji,
+		Games:   p.Games,
+		Wins:    p.Wins,
+		Seconds: p.Seconds,
+		Points:  p.Points,
+		PPG:     p.PPG,
+	}
+}
+
+func toAPIPlayerRef(p db.Player) apiPlayerRef {
+	return apiPlayerRef{ID: p.ID, Name: p.Name, Emoji: p.Emoji}
+}
+
+func toAPIGame(g db.Game) apiGame {
+	participants := make([]apiPlayerRef, len(g.Participants))
+	for i, p := range g.Participants {
+		participants[i] = toAPIPlayerRef(p)
+	}
+	return apiGame{
+		ID:           g.ID,
+		PlayedAt:     g.PlayedAt,
+		Winner:       toAPIPlayerRef(g.Winner),
+		Second:       toAPIPlayerRef(g.Second),
+		Participants: participants,
+		CreatedBy:    g.CreatedBy,
+		SessionID:    g.SessionID,
+	}
+}
+
+func toAPIGames(games []db.Game) []apiGame {
+	result := make([]apiGame, len(games))
+	for i, g := range games {
+		result[i] = toAPIGame(g)
+	}
+	return result
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("could not write json response", "error", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, apiError{Error: msg})
+}
+
+func writeAPIMethodNotAllowed(w http.ResponseWriter, allow ...string) {
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// apiGet rejects anything but GET requests with a JSON error.
+func apiGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		writeAPIMethodNotAllowed(w, http.MethodGet)
+		return false
+	}
+	return true
+}
+
+func (a *App) handleAPINotFound(w http.ResponseWriter, r *http.Request) {
+	writeAPIError(w, http.StatusNotFound, "not found")
+}
+
+func (a *App) handleAPILeaderboard(w http.ResponseWriter, r *http.Request) {
+	if !apiGet(w, r) {
+		return
+	}
+
+	players, err := a.store.ListPlayersByPoints()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load leaderboard")
+		return
+	}
+
+	entries := make([]apiLeaderboardEntry, len(players))
+	for i, p := range players {
+		entries[i] = apiLeaderboardEntry{Rank: i + 1, apiPlayer: toAPIPlayer(p)}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (a *App) handleAPIPlayers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.listAPIPlayers(w, r)
+	case http.MethodPost:
+		a.createAPIPlayer(w, r)
+	default:
+		writeAPIMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (a *App) listAPIPlayers(w http.ResponseWriter, r *http.Request) {
+	players, err := a.store.ListPlayersByName()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load players")
+		return
+	}
+
+	result := make([]apiPlayer, len(players))
+	for i, p := range players {
+		result[i] = toAPIPlayer(p)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (a *App) handleAPIPlayer(w http.ResponseWriter, r *http.Request) {
+	if !apiGet(w, r) {
+		return
+	}
+
+	playerID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid player id")
+		return
+	}
+
+	players, err := a.store.ListPlayersByPoints()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load players")
+		return
+	}
+
+	var detail *apiPlayerDetail
+	for i, p := range players {
+		if p.ID == playerID {
+			detail = &apiPlayerDetail{Rank: i + 1, apiPlayer: toAPIPlayer(p)}
+			break
+		}
+	}
+	if detail == nil {
+		writeAPIError(w, http.StatusNotFound, "player not found")
+		return
+	}
+
+	gameHistory, err := a.store.PlayerGameHistory(playerID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load player history")
+		return
+	}
+	detail.GameHistory = make([]apiGameHistoryEntry, len(gameHistory))
+	for i, h := range gameHistory {
+		detail.GameHistory[i] = apiGameHistoryEntry(h)
+	}
+
+	rankHistory, err := a.store.PlayerRankHistory(playerID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load player rank history")
+		return
+	}
+	detail.RankHistory = make([]apiRankHistoryEntry, len(rankHistory))
+	for i, h := range rankHistory {
+		detail.RankHistory[i] = apiRankHistoryEntry(h)
+	}
+
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (a *App) handleAPIGames(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		a.listAPIGames(w, r)
+	case http.MethodPost:
+		a.createAPIGame(w, r)
+	default:
+		writeAPIMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (a *App) listAPIGames(w http.ResponseWriter, r *http.Request) {
+	games, err := a.store.ListGames(0)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load games")
+		return
+	}
+	writeJSON(w, http.StatusOK, toAPIGames(games))
+}
+
+func (a *App) handleAPIGame(w http.ResponseWriter, r *http.Request) {
+	if !apiGet(w, r) {
+		return
+	}
+
+	gameID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid game id")
+		return
+	}
+
+	game, err := a.store.GetGame(gameID)
+	if errors.Is(err, db.ErrNotFound) {
+		writeAPIError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load game")
+		return
+	}
+	writeJSON(w, http.StatusOK, toAPIGame(game))
+}
+
+func (a *App) handleAPIH2H(w http.ResponseWriter, r *http.Request) {
+	if !apiGet(w, r) {
+		return
+	}
+
+	player1ID, err1 := strconv.Atoi(r.URL.Query().Get("player1"))
+	player2ID, err2 := strconv.Atoi(r.URL.Query().Get("player2"))
+	if err1 != nil || err2 != nil {
+		writeAPIError(w, http.StatusBadRequest, "player1 and player2 must be player ids")
+		return
+	}
+	if player1ID == player2ID {
+		writeAPIError(w, http.StatusBadRequest, "player1 and player2 must be different players")
+		return
+	}
+
+	// GetH2HStats doesn't tell a missing player apart from other errors
+	found, err := a.store.PlayersByIDs([]int{player1ID, player2ID})
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load players")
+		return
+	}
+	if len(found) != 2 {
+		writeAPIError(w, http.StatusNotFound, "player not found")
+		return
+	}
+
+	stats, err := a.store.GetH2HStats(player1ID, player2ID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load head-to-head stats")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiH2H{
+		Player1:       toAPIPlayer(stats.Player1),
+		Player2:       toAPIPlayer(stats.Player2),
+		SharedGames:   stats.SharedGames,
+		Player1Stats:  toAPIPlayer(stats.Player1Stats),
+		Player2Stats:  toAPIPlayer(stats.Player2Stats),
+		Player1Ahead:  stats.Player1Ahead,
+		Player2Ahead:  stats.Player2Ahead,
+		BothOffPodium: stats.BothOffPodium,
+		Games:         toAPIGames(stats.SharedGamesList),
+	})
+}
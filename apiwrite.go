@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// maxAPIBody caps the size of JSON request bodies.
+const maxAPIBody = 1 << 20
+
+type apiGameRequest struct {
+	PlayedAt     string `json:"played_at" api:"optional"`
+	Participants []int  `json:"participants"`
+	WinnerID     int    `json:"winner_id"`
+	SecondID     int    `json:"second_id"`
+	SessionID    int    `json:"session_id" api:"optional"`
+}
+
+type apiPlayerRequest struct {
+	Name string `json:"name"`
+}
+
+// requireAPIAuth is requireAuth with a JSON error body.
+func requireAPIAuth(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user, pass, ok := r.BasicAuth()
+	user = strings.TrimSpace(user)
+	if !ok || pass != password() || user == "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Hest"`)
+		writeAPIError(w, http.StatusUnauthorized, "authorization required")
+		return "", false
+	}
+	return user, true
+}
+
+// decodeAPIRequest decodes a JSON request body into v, rejecting unknown
+// fields so typos don't pass silently.
+func decodeAPIRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeAPIValidation(w http.ResponseWriter, status int, fields []apiFieldError) {
+	writeJSON(w, status, apiError{Error: "validation failed", Fields: fields})
+}
+
+func (a *App) createAPIGame(w http.ResponseWriter, r *http.Request) {
+	username, ok := requireAPIAuth(w, r)
+	if !ok {
+		return
+	}
+
+	var req apiGameRequest
+	if !decodeAPIRequest(w, r, &req) {
+		return
+	}
+
+	// Same checks as saveGameCommon, collected instead of stopping at the first
+	var fields []apiFieldError
+	uniqueIDs := db.Dedupe(req.Participants)
+	if len(uniqueIDs) < 2 {
+		fields = append(fields, apiFieldError{"participants", "Pick at least two players."})
+	} else {
+		players, err := a.store.PlayersByIDs(uniqueIDs)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "failed to load players")
+			return
+		}
+		if len(players) < len(uniqueIDs) {
+			fields = append(fields, apiFieldError{"participants", "Unknown player selected."})
+		}
+	}
+	if field, msg := validatePlacement(req.WinnerID, req.SecondID, uniqueIDs); msg != "" {
+		fields = append(fields, apiFieldError{field, msg})
+	}
+	playedAt, msg := parsePlayedAt(req.PlayedAt)
+	if msg != "" {
+		fields = append(fields, apiFieldError{"played_at", msg})
+	}
+	if req.SessionID < 0 {
+		fields = append(fields, apiFieldError{"session_id", "Invalid session."})
+	}
+	if len(fields) > 0 {
+		writeAPIValidation(w, http.StatusUnprocessableEntity, fields)
+		return
+	}
+
+	gameID, err := a.store.AddGame(playedAt, uniqueIDs, req.WinnerID, req.SecondID, username, req.SessionID)
+	if err != nil {
+		slog.Error("could not add game", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not add game")
+		return
+	}
+
+	game, err := a.store.GetGame(gameID)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load game")
+		return
+	}
+	w.Header().Set("Location", "/api/v1/games/"+strconv.Itoa(gameID))
+	writeJSON(w, http.StatusCreated, toAPIGame(game))
+}
+
+func (a *App) createAPIPlayer(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAPIAuth(w, r); !ok {
+		return
+	}
+
+	var req apiPlayerRequest
+	if !decodeAPIRequest(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeAPIValidation(w, http.StatusUnprocessableEntity, []apiFieldError{{"name", "Name required."}})
+		return
+	}
+
+	playerID, err := a.store.AddPlayer(name)
+	if errors.Is(err, db.ErrDuplicate) {
+		writeAPIValidation(w, http.StatusConflict, []apiFieldError{{"name", "A player with that name already exists."}})
+		return
+	}
+	if err != nil {
+		slog.Error("could not add player", "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "could not add player")
+		return
+	}
+
+	players, err := a.store.PlayersByIDs([]int{playerID})
+	if err != nil || len(players) == 0 {
+		writeAPIError(w, http.StatusInternalServerError, "failed to load player")
+		return
+	}
+	w.Header().Set("Location", "/api/v1/players/"+strconv.Itoa(playerID))
+	writeJSON(w, http.StatusCreated, toAPIPlayer(players[0]))
+}
@@ -29,14 +29,41 @@ func (a *App) routes() http.Handler {
 	staticServer := http.FileServer(http.FS(staticContent))
 	mux.Handle("/static/", http.StripPrefix("/static/", staticServer))
 	mux.HandleFunc("/", a.handleLeaderboard)
+	mux.HandleFunc("/leaderboard.csv", a.handleLeaderboardCSV)
 	mux.HandleFunc("/games", a.handleGames)
+	mux.HandleFunc("/games.csv", a.handleGamesCSV)
 	mux.HandleFunc("/games/save", a.handleSaveGame)
 	mux.HandleFunc("/games/save-and-new", a.handleSaveAndNewGame)
+	mux.HandleFunc("/sessions", a.handleSessions)
 	mux.HandleFunc("/new", a.handleNewGame)
+	mux.HandleFunc("/import", a.handleImport)
+	mux.HandleFunc("/admin", a.handleAdmin)
+	mux.HandleFunc("/admin/dump.json", a.handleDump)
+	mux.HandleFunc("/admin/restore", a.handleRestore)
+	mux.HandleFunc("/admin/backup.db", a.handleBackup)
 	mux.HandleFunc("/new/score", a.handleScoreGame)
 	mux.HandleFunc("/players", a.handleAddPlayer)
 	mux.HandleFunc("/player", a.handlePlayerDetail)
+	mux.HandleFunc("/player/year", a.handleYearReview)
+	mux.HandleFunc("/player/feed.atom", a.handlePlayerFeed)
+	mux.HandleFunc("/feed.atom", a.handleFeed)
+	mux.HandleFunc("/calendar.ics", a.handleCalendar)
+	mux.HandleFunc("/badge/player.svg", a.handlePlayerBadge)
+	mux.HandleFunc("/badge/leaderboard.svg", a.handleLeaderboardBadge)
 	mux.HandleFunc("/h2h", a.handleH2H)
+	mux.HandleFunc("/h2h/matrix", a.handleH2HMatrix)
+	mux.HandleFunc("/network", a.handleNetwork)
+	mux.HandleFunc("/stats", a.handleStats)
+	mux.HandleFunc("/compare", a.handleCompare)
+	mux.HandleFunc("/bump", a.handleBump)
+	mux.HandleFunc("/whatif", a.handleWhatIf)
+	mux.HandleFunc("/onthisday", a.handleOnThisDay)
+	mux.HandleFunc("/api/", a.handleAPINotFound)
+	mux.HandleFunc("/api/openapi.json", a.handleOpenAPI)
+	mux.HandleFunc("/api/docs", a.handleAPIDocs)
+	for _, route := range a.apiRoutes() {
+		mux.HandleFunc(route.Pattern, route.Handler)
+	}
 	return mux
 }
 
@@ -67,7 +94,7 @@ func (a *App) ListPlayers() ([]Player, error) {
 }
 
 func (a *App) listGames() ([]Game, error) {
-	games, err := a.store.ListGames()
+	games, err := a.store.ListGames(0)
 	if err != nil {
 		return nil, err
 	}
@@ -116,12 +143,17 @@ func parsePlayer(id string) (int, error) {
 	return strconv.Atoi(strings.TrimSpace(id))
 }
 
-func validatePlacement(winnerID, secondID int, participantIDs []int) string {
-	if winnerID == 0 || secondID == 0 {
-		return "Pick a winner and a 2nd place."
+// validatePlacement checks the winner and 2nd place against the participants
+// and returns the offending form field and a message, or empty strings.
+func validatePlacement(winnerID, secondID int, participantIDs []int) (field, msg string) {
+	if winnerID == 0 {
+		return "winner_id", "Pick a winner and a 2nd place."
+	}
+	if secondID == 0 {
+		return "second_id", "Pick a winner and a 2nd place."
 	}
 	if winnerID == secondID {
-		return "Winner and 2nd place must be different players."
+		return "second_id", "Winner and 2nd place must be different players."
 	}
 
 	idSet := make(map[int]struct{}, len(participantIDs))
@@ -129,12 +161,12 @@ func validatePlacement(winnerID, secondID int, participantIDs []int) string {
 		idSet[id] = struct{}{}
 	}
 	if _, ok := idSet[winnerID]; !ok {
-		return "Winner must be part of the game."
+		return "winner_id", "Winner must be part of the game."
 	}
 	if _, ok := idSet[secondID]; !ok {
-		return "2nd place must be part of the game."
+		return "second_id", "2nd place must be part of the game."
 	}
-	return ""
+	return "", ""
 }
 
 func parsePlayedAt(raw string) (time.Time, string) {
@@ -148,3 +180,16 @@ func parsePlayedAt(raw string) (time.Time, string) {
 	}
 	return playedAt, ""
 }
+
+// parseSession parses an optional explicit session id, returning 0 if empty.
+func parseSession(raw string) (int, string) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, ""
+	}
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0, "Invalid session."
+	}
+	return id, ""
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+const (
+	defaultKeepDaily  = 7
+	defaultKeepWeekly = 4
+
+	// backupInterval is how often the scheduled mode checks whether today's
+	// backup has been taken.
+	backupInterval = time.Hour
+
+	dailyPrefix  = "hest-daily-"
+	weeklyPrefix = "hest-weekly-"
+)
+
+// backupConfig is where scheduled backups go and how many are kept.
+type backupConfig struct {
+	Dir        string
+	KeepDaily  int
+	KeepWeekly int
+}
+
+// backupSettings returns the scheduled backup settings from HEST_BACKUP_DIR,
+// HEST_BACKUP_KEEP_DAILY and HEST_BACKUP_KEEP_WEEKLY, and whether scheduled
+// backups are enabled.
+func backupSettings() (backupConfig, bool) {
+	cfg := backupConfig{
+		Dir:        strings.TrimSpace(os.Getenv("HEST_BACKUP_DIR")),
+		KeepDaily:  envInt("HEST_BACKUP_KEEP_DAILY", defaultKeepDaily),
+		KeepWeekly: envInt("HEST_BACKUP_KEEP_WEEKLY", defaultKeepWeekly),
+	}
+	return cfg, cfg.Dir != ""
+}
+
+func envInt(name string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		slog.Warn("invalid "+name, "value", raw)
+		return fallback
+	}
+	return n
+}
+
+// rotateBackups takes today's daily and this week's weekly backup if they
+// don't exist yet, and removes the oldest beyond what cfg keeps.
+func rotateBackups(ctx context.Context, store *db.Store, cfg backupConfig, now time.Time) error {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return err
+	}
+
+	year, week := now.ISOWeek()
+	for _, name := range []string{
+		dailyPrefix + now.Format(dateLayout) + ".db",
+		fmt.Sprintf("%s%d-W%02d.db", weeklyPrefix, year, week),
+	} {
+		path := filepath.Join(cfg.Dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := store.Backup(ctx, path); err != nil {
+			return err
+		}
+		slog.Info("backup written", "path", path)
+	}
+
+	if err := pruneBackups(cfg.Dir, dailyPrefix, cfg.KeepDaily); err != nil {
+		return err
+	}
+	return pruneBackups(cfg.Dir, weeklyPrefix, cfg.KeepWeekly)
+}
+
+// pruneBackups keeps the newest backups with the prefix. The dates in the
+// names sort the same way as the backups were taken.
+func pruneBackups(dir, prefix string, keep int) error {
+	names, err := listBackups(dir, prefix)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(names); i++ {
+		if err := os.Remove(filepath.Join(dir, names[i])); err != nil {
+			return err
+		}
+		slog.Info("backup removed", "path", filepath.Join(dir, names[i]))
+	}
+	return nil
+}
+
+// listBackups returns the backup file names with the prefix, newest first.
+func listBackups(dir, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && strings.HasSuffix(e.Name(), ".db") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// scheduleBackups rotates backups now and then every backupInterval until ctx
+// is done.
+func scheduleBackups(ctx context.Context, store *db.Store, cfg backupConfig) {
+	slog.Info("scheduled backups enabled", "dir", cfg.Dir, "daily", cfg.KeepDaily, "weekly", cfg.KeepWeekly)
+	ticker := time.NewTicker(backupInterval)
+	defer ticker.Stop()
+	for {
+		if err := rotateBackups(ctx, store, cfg, time.Now()); err != nil {
+			slog.Error("scheduled backup failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handleBackup downloads a consistent snapshot of the live database.
+func (a *App) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := requireAuth(w, r); !ok {
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "hest-backup-")
+	if err != nil {
+		http.Error(w, "failed to create backup", http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hest.db")
+	if err := a.store.Backup(r.Context(), path); err != nil {
+		slog.Error("could not back up database", "error", err)
+		http.Error(w, "failed to create backup", http.StatusInternalServerError)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		http.Error(w, "failed to read backup", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", `attachment; filename="hest-`+time.Now().Format(dateLayout)+`.db"`)
+	if _, err := io.Copy(w, file); err != nil {
+		slog.Error("could not send backup", "error", err)
+	}
+}
+
+// runBackup is the backup command. With a file it writes a single snapshot,
+// otherwise it rotates backups in the backup directory like the scheduled
+// mode does.
+func runBackup(store *db.Store, args []string) error {
+	cfg, _ := backupSettings()
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	fs.StringVar(&cfg.Dir, "dir", cfg.Dir, "backup directory, defaults to HEST_BACKUP_DIR")
+	fs.IntVar(&cfg.KeepDaily, "keep-daily", cfg.KeepDaily, "number of daily backups to keep")
+	fs.IntVar(&cfg.KeepWeekly, "keep-weekly", cfg.KeepWeekly, "number of weekly backups to keep")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hest backup [-dir dir] [-keep-daily n] [-keep-weekly n] [file.db]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if fs.NArg() == 1 {
+		return store.Backup(ctx, fs.Arg(0))
+	}
+	if fs.NArg() > 1 || cfg.Dir == "" {
+		fs.Usage()
+		return errors.New("expected a file or a backup directory")
+	}
+	return rotateBackups(ctx, store, cfg, time.Now())
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// touch creates empty files in dir.
+func touch(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestListBackups(t *testing.T) {
+	dir := t.TempDir()
+	touch(t, dir,
+		"hest-daily-2024-05-02.db",
+		"hest-daily-2024-04-30.db",
+		"hest-daily-2024-05-01.db",
+		"hest-daily-2024-05-03.db-journal",
+		"hest-weekly-2024-W18.db",
+		"hest.db",
+	)
+	if err := os.Mkdir(filepath.Join(dir, "hest-daily-2024-05-04.db"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := listBackups(dir, dailyPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"hest-daily-2024-05-02.db", "hest-daily-2024-05-01.db", "hest-daily-2024-04-30.db"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+
+	names, err = listBackups(filepath.Join(dir, "missing"), dailyPrefix)
+	if err != nil || names != nil {
+		t.Errorf("missing dir: got %v, %v, want no backups and no error", names, err)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	daily := []string{
+		"hest-daily-2024-04-29.db",
+		"hest-daily-2024-04-30.db",
+		"hest-daily-2024-05-01.db",
+	}
+	others := []string{"hest-weekly-2024-W17.db", "hest.db"}
+	all := append(append([]string{}, daily...), others...)
+
+	tests := []struct {
+		name string
+		keep int
+		want []string
+	}{
+		{name: "keep none", keep: 0, want: others},
+		{name: "keep newest", keep: 1, want: []string{"hest-daily-2024-05-01.db", "hest-weekly-2024-W17.db", "hest.db"}},
+		{name: "keep all", keep: 3, want: all},
+		{name: "keep more than there are", keep: 10, want: all},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			touch(t, dir, daily...)
+			touch(t, dir, others...)
+
+			if err := pruneBackups(dir, dailyPrefix, tt.keep); err != nil {
+				t.Fatal(err)
+			}
+			if got := dirNames(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateBackups(t *testing.T) {
+	store := newTestApp(t).store
+	cfg := backupConfig{Dir: filepath.Join(t.TempDir(), "backups"), KeepDaily: 2, KeepWeekly: 2}
+
+	// Around new year the ISO week belongs to the neighbouring year
+	for _, day := range []string{"2020-12-30", "2021-01-03", "2021-01-03", "2021-01-04"} {
+		now, err := time.Parse(dateLayout, day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rotateBackups(context.Background(), store, cfg, now.Add(12*time.Hour)); err != nil {
+			t.Fatalf("rotate on %s: %v", day, err)
+		}
+	}
+
+	want := []string{
+		"hest-daily-2021-01-03.db",
+		"hest-daily-2021-01-04.db",
+		"hest-weekly-2020-W53.db",
+		"hest-weekly-2021-W01.db",
+	}
+	if got := dirNames(t, cfg.Dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// badgeMaxAge is how long clients and proxies may cache a badge.
+const badgeMaxAge = 5 * time.Minute
+
+const (
+	badgeLabelColor = "#555"
+	badgeColor      = "#464646"
+)
+
+// podiumColors colour the badge of the top three players.
+var podiumColors = []string{"#dfb317", "#9f9f9f", "#c0763c"}
+
+// badge is a shields style badge with a label on the left and a message on
+// the right.
+type badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+type badgeLayout struct {
+	badge
+	Width        int
+	LabelWidth   int
+	MessageWidth int
+	LabelX       int
+	MessageX     int
+}
+
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}">
+  <title>{{.Label}}: {{.Message}}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="{{.Width}}" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="{{.LabelWidth}}" height="20" fill="` + badgeLabelColor + `"/>
+    <rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/>
+    <rect width="{{.Width}}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="{{.LabelX}}" y="15" fill="#010101" fill-opacity=".3">{{.Label}}</text>
+    <text x="{{.LabelX}}" y="14">{{.Label}}</text>
+    <text x="{{.MessageX}}" y="15" fill="#010101" fill-opacity=".3">{{.Message}}</text>
+    <text x="{{.MessageX}}" y="14">{{.Message}}</text>
+  </g>
+</svg>
+`))
+
+// textWidth estimates the width of s in pixels at the badge font size. There
+// are no font metrics to go by, so wide characters such as emoji count double.
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x2000:
+			width += 14
+		case r == ' ' || r == '.' || r == ',' || r == ':' || r == 'i' || r == 'l':
+			width += 4
+		default:
+			width += 7
+		}
+	}
+	return width
+}
+
+func (b badge) render() ([]byte, error) {
+	layout := badgeLayout{
+		badge:        b,
+		LabelWidth:   textWidth(b.Label) + 10,
+		MessageWidth: textWidth(b.Message) + 10,
+	}
+	layout.Width = layout.LabelWidth + layout.MessageWidth
+	layout.LabelX = layout.LabelWidth / 2
+	layout.MessageX = layout.LabelWidth + layout.MessageWidth/2
+
+	var buf bytes.Buffer
+	if err := badgeTemplate.Execute(&buf, layout); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBadge serves a badge with caching headers. The ETag lets clients
+// revalidate cheaply once the badge has expired. Error badges aren't cached,
+// so a fixed link or a new player shows up right away.
+func writeBadge(w http.ResponseWriter, r *http.Request, status int, b badge) {
+	svg, err := b.render()
+	if err != nil {
+		http.Error(w, "render error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	if status != http.StatusOK {
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_, _ = w.Write(svg)
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(badgeMaxAge.Seconds())))
+	sum := sha256.Sum256(svg)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(svg))
+}
+
+// badgeMethod rejects anything but GET and HEAD. HEAD is served by
+// http.ServeContent and used by badge embedders to check a badge exists.
+func badgeMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return true
+}
+
+// rankColor returns the badge colour for a leaderboard rank.
+func rankColor(rank int) string {
+	if rank >= 1 && rank <= len(podiumColors) {
+		return podiumColors[rank-1]
+	}
+	return badgeColor
+}
+
+func (a *App) handlePlayerBadge(w http.ResponseWriter, r *http.Request) {
+	if !badgeMethod(w, r) {
+		return
+	}
+
+	playerID, err := parsePlayer(r.URL.Query().Get("id"))
+	if err != nil {
+		writeBadge(w, r, http.StatusBadRequest, badge{Label: "hest", Message: "ugyldigt id", Color: badgeColor})
+		return
+	}
+
+	players, err := a.store.ListPlayersByPoints()
+	if err != nil {
+		http.Error(w, "failed to load leaderboard", http.StatusInternalServerError)
+		return
+	}
+
+	for i, p := range players {
+		if p.ID != playerID {
+			continue
+		}
+		writeBadge(w, r, http.StatusOK, badge{
+			Label:   strings.TrimSpace(p.Emoji + " " + p.Name),
+			Message: fmt.Sprintf("#%d · %d pts · %.2f ppg", i+1, p.Points, p.PPG),
+			Color:   rankColor(i + 1),
+		})
+		return
+	}
+	writeBadge(w, r, http.StatusNotFound, badge{Label: "hest", Message: "ukendt spiller", Color: badgeColor})
+}
+
+func (a *App) handleLeaderboardBadge(w http.ResponseWriter, r *http.Request) {
+	if !badgeMethod(w, r) {
+		return
+	}
+
+	players, err := a.store.ListPlayersByPoints()
+	if err != nil {
+		http.Error(w, "failed to load leaderboard", http.StatusInternalServerError)
+		return
+	}
+
+	var podium []string
+	for i, p := range players {
+		if i == len(podiumColors) {
+			break
+		}
+		podium = append(podium, fmt.Sprintf("%d. %s %s %d", i+1, p.Emoji, p.Name, p.Points))
+	}
+	message := strings.Join(podium, " · ")
+	if message == "" {
+		message = "ingen kampe"
+	}
+	writeBadge(w, r, http.StatusOK, badge{Label: "🐴 hest", Message: message, Color: podiumColors[0]})
+}
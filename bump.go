@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// bumpSeries is a player's rank after each game on the bump chart, nil
+// before their first game.
+type bumpSeries struct {
+	Player Player
+	Ranks  []*int
+}
+
+type bumpView struct {
+	Path    string
+	Title   string
+	Labels  []string
+	Series  []bumpSeries
+	Players int
+}
+
+func newBumpView() bumpView {
+	return bumpView{
+		Path:  "/bump",
+		Title: "Placeringer",
+	}
+}
+
+// withHistory turns the rank history into one series per player aligned on
+// the games.
+func (b bumpView) withHistory(players []Player, history []db.RankHistoryEntry) bumpView {
+	index := make(map[int]int, len(players))
+	b.Series = make([]bumpSeries, len(players))
+	for i, p := range players {
+		index[p.ID] = i
+		b.Series[i] = bumpSeries{Player: p}
+	}
+
+	lastGame := 0
+	for _, h := range history {
+		if h.GameID != lastGame {
+			lastGame = h.GameID
+			b.Labels = append(b.Labels, h.PlayedAt.Format(dateLayout))
+			for i := range b.Series {
+				b.Series[i].Ranks = append(b.Series[i].Ranks, nil)
+			}
+		}
+		i, ok := index[h.PlayerID]
+		if !ok {
+			continue
+		}
+		rank := h.Rank
+		b.Series[i].Ranks[len(b.Labels)-1] = &rank
+	}
+
+	// Leave out players who never played
+	series := b.Series[:0]
+	for _, s := range b.Series {
+		if s.Player.Games > 0 {
+			series = append(series, s)
+		}
+	}
+	b.Series = series
+	b.Players = len(series)
+	return b
+}
+
+func (a *App) handleBump(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	players, err := a.Leaderboard()
+	if err != nil {
+		http.Error(w, "failed to load players", http.StatusInternalServerError)
+		return
+	}
+
+	history, err := a.store.RankHistory()
+	if err != nil {
+		http.Error(w, "failed to load rank history", http.StatusInternalServerError)
+		return
+	}
+
+	view := newBumpView().withHistory(players, history)
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/bump.html")
+}
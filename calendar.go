@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405Z"
+
+	// icsLineLength is the longest a content line may be in octets before it
+	// has to be folded.
+	icsLineLength = 75
+)
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// gameDay is the games played on a single date, oldest first.
+type gameDay struct {
+	Date  time.Time
+	Games []db.Game
+}
+
+// groupGameDays groups games by the date they were played on, newest date
+// first. Games are expected newest first, as ListGames returns them.
+func groupGameDays(games []db.Game) []gameDay {
+	var days []gameDay
+	for _, g := range games {
+		date := g.PlayedAt.Format(dateLayout)
+		if n := len(days); n == 0 || days[n-1].Date.Format(dateLayout) != date {
+			days = append(days, gameDay{Date: g.PlayedAt})
+		}
+		day := &days[len(days)-1]
+		day.Games = append([]db.Game{g}, day.Games...)
+	}
+	return days
+}
+
+// describe summarises the results of the day, one line per game.
+func (d gameDay) describe() string {
+	var b strings.Builder
+	for i, g := range d.Games {
+		names := make([]string, len(g.Participants))
+		for j, p := range g.Participants {
+			names[j] = p.Name
+		}
+		fmt.Fprintf(&b, "%d. %s vandt foran %s (%s)\n", i+1, g.Winner.Name, g.Second.Name, strings.Join(names, ", "))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeICSLine writes a content line, folding it at icsLineLength octets
+// without splitting a UTF-8 character.
+func writeICSLine(w io.Writer, line string) {
+	limit := icsLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		io.WriteString(w, line[:cut]+"\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space that counts towards the limit
+		limit = icsLineLength - 1
+	}
+	io.WriteString(w, line+"\r\n")
+}
+
+func (a *App) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	games, err := a.store.ListGames(0)
+	if err != nil {
+		http.Error(w, "failed to load games", http.StatusInternalServerError)
+		return
+	}
+	anchors, err := a.store.SessionAnchors(games)
+	if err != nil {
+		http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="hest.ics"`)
+
+	base := baseURL(r)
+	stamp := time.Now().UTC().Format(icsDateTime)
+	writeICSLine(w, "BEGIN:VCALENDAR")
+	writeICSLine(w, "VERSION:2.0")
+	writeICSLine(w, "PRODID:-//hest//hest//DA")
+	writeICSLine(w, "CALSCALE:GREGORIAN")
+	writeICSLine(w, "X-WR-CALNAME:Hest")
+	for _, day := range groupGameDays(games) {
+		date := day.Date.Format(dateLayout)
+		summary := fmt.Sprintf("Hest: %d kampe", len(day.Games))
+		if len(day.Games) == 1 {
+			summary = "Hest: 1 kamp"
+		}
+
+		writeICSLine(w, "BEGIN:VEVENT")
+		writeICSLine(w, "UID:"+date+"@"+r.Host)
+		writeICSLine(w, "DTSTAMP:"+stamp)
+		writeICSLine(w, "DTSTART;VALUE=DATE:"+day.Date.Format(icsDate))
+		writeICSLine(w, "DTEND;VALUE=DATE:"+day.Date.AddDate(0, 0, 1).Format(icsDate))
+		writeICSLine(w, "SUMMARY:"+icsEscaper.Replace(summary))
+		writeICSLine(w, "DESCRIPTION:"+icsEscaper.Replace(day.describe()))
+		// A date can hold games from several sessions, link to the first
+		writeICSLine(w, "URL:"+base+"/sessions#"+anchors[day.Games[0].ID])
+		writeICSLine(w, "END:VEVENT")
+	}
+	writeICSLine(w, "END:VCALENDAR")
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// runCommand runs a command line subcommand instead of the web server.
+func runCommand(store *db.Store, name string, args []string) error {
+	switch name {
+	case "import":
+		return runImport(store, args)
+	case "dump":
+		return runDump(store, args)
+	case "restore":
+		return runRestore(store, args)
+	case "backup":
+		return runBackup(store, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+type compareView struct {
+	Path       string
+	Title      string
+	Players    []Player
+	Selected   map[int]bool
+	Labels     []string
+	Comparison db.Comparison
+}
+
+func newCompareView(players []Player) compareView {
+	return compareView{
+		Path:     "/compare",
+		Title:    "Sammenlign",
+		Players:  players,
+		Selected: make(map[int]bool),
+	}
+}
+
+func (c compareView) withComparison(comparison db.Comparison) compareView {
+	c.Comparison = comparison
+	c.Labels = make([]string, len(comparison.Dates))
+	for i, d := range comparison.Dates {
+		c.Labels[i] = d.Format(dateLayout)
+	}
+	for _, s := range comparison.Series {
+		c.Selected[s.Player.ID] = true
+	}
+	return c
+}
+
+func (a *App) handleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ids, err := parseIDs(r.URL.Query()["id"])
+	if err != nil {
+		http.Error(w, "bad player selection", http.StatusBadRequest)
+		return
+	}
+
+	players, err := a.ListPlayers()
+	if err != nil {
+		http.Error(w, "failed to load players", http.StatusInternalServerError)
+		return
+	}
+
+	view := newCompareView(players)
+	if len(ids) > 0 {
+		comparison, err := a.store.CompareHistory(ids)
+		if err != nil {
+			http.Error(w, "failed to load history", http.StatusInternalServerError)
+			return
+		}
+		view = view.withComparison(comparison)
+	}
+
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/compare.html")
+}
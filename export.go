@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// participantSeparator joins participant names in a single CSV field.
+const participantSeparator = ";"
+
+// formulaPrefixes start a cell that spreadsheets would evaluate as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// csvText guards a user supplied value against CSV formula injection by
+// prefixing cells a spreadsheet would evaluate with a quote.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// parseDateRange reads the optional from and to query parameters.
+func parseDateRange(r *http.Request) (db.DateRange, error) {
+	var dr db.DateRange
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &dr.From}, {"to", &dr.To}} {
+		raw := strings.TrimSpace(r.URL.Query().Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(dateLayout, raw)
+		if err != nil {
+			return dr, fmt.Errorf("invalid %s date", bound.name)
+		}
+		*bound.dst = t
+	}
+	if !dr.From.IsZero() && !dr.To.IsZero() && dr.To.Before(dr.From) {
+		return dr, fmt.Errorf("to is before from")
+	}
+	return dr, nil
+}
+
+// exportFilename names a CSV download after the export and its date range.
+func exportFilename(name string, dr db.DateRange) string {
+	if !dr.From.IsZero() {
+		name += "-from-" + dr.From.Format(dateLayout)
+	}
+	if !dr.To.IsZero() {
+		name += "-to-" + dr.To.Format(dateLayout)
+	}
+	return "hest-" + name + ".csv"
+}
+
+// startCSV writes the download headers and returns a CSV writer for the body.
+func startCSV(w http.ResponseWriter, filename string) *csv.Writer {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	return csv.NewWriter(w)
+}
+
+func (a *App) handleGamesCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dr, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cw := startCSV(w, exportFilename("games", dr))
+	_ = cw.Write([]string{"date", "winner", "second", "participants", "created_by"})
+	err = a.store.EachGame(dr, func(g db.Game) error {
+		names := make([]string, len(g.Participants))
+		for i, p := range g.Participants {
+			names[i] = p.Name
+		}
+		return cw.Write([]string{
+			g.PlayedAt.Format(dateLayout),
+			csvText(g.Winner.Name),
+			csvText(g.Second.Name),
+			csvText(strings.Join(names, participantSeparator)),
+			csvText(g.CreatedBy),
+		})
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		// Headers are already sent, so all we can do is log it
+		slog.Error("could not export games", "error", err)
+	}
+}
+
+func (a *App) handleLeaderboardCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dr, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cw := startCSV(w, exportFilename("leaderboard", dr))
+	_ = cw.Write([]string{"rank", "name", "games", "wins", "seconds", "points", "ppg"})
+	err = a.store.EachStanding(dr, func(rank int, p db.Player) error {
+		return cw.Write([]string{
+			strconv.Itoa(rank),
+			csvText(p.Name),
+			strconv.Itoa(p.Games),
+			strconv.Itoa(p.Wins),
+			strconv.Itoa(p.Seconds),
+			strconv.Itoa(p.Points),
+			strconv.FormatFloat(p.PPG, 'f', 2, 64),
+		})
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	if err != nil {
+		slog.Error("could not export leaderboard", "error", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// feedLength is the number of games in a feed.
+const feedLength = 50
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  *atomAuthor `xml:"author,omitempty"`
+	Link    atomLink    `xml:"link"`
+	Summary string      `xml:"summary"`
+}
+
+// baseURL returns the scheme and host the request was made to, so feeds get
+// absolute links behind a TLS terminating proxy too.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// newAtomFeed builds a feed with one entry per game linking to its session.
+// self is the path of the feed and alternate the page it mirrors. anchors are
+// the session anchors of the games as returned by SessionAnchors.
+func newAtomFeed(base, title, self, alternate string, games []db.Game, anchors map[int]string) atomFeed {
+	feed := atomFeed{
+		Title:  title,
+		ID:     base + self,
+		Author: atomAuthor{Name: "Hest"},
+		Links: []atomLink{
+			{Href: base + self, Rel: "self", Type: "application/atom+xml"},
+			{Href: base + alternate, Rel: "alternate", Type: "text/html"},
+		},
+	}
+
+	// An empty feed is as old as the epoch
+	updated := time.Unix(0, 0)
+	for _, g := range games {
+		if g.PlayedAt.After(updated) {
+			updated = g.PlayedAt
+		}
+
+		names := make([]string, len(g.Participants))
+		for i, p := range g.Participants {
+			names[i] = p.Name
+		}
+		entry := atomEntry{
+			Title:   fmt.Sprintf("%s %s vandt, %s %s blev nummer 2", g.Winner.Emoji, g.Winner.Name, g.Second.Emoji, g.Second.Name),
+			ID:      base + "/api/v1/games/" + strconv.Itoa(g.ID),
+			Updated: g.PlayedAt.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: base + "/sessions#" + anchors[g.ID], Rel: "alternate", Type: "text/html"},
+			Summary: fmt.Sprintf("%s: %s vandt foran %s. Deltagere: %s.",
+				g.PlayedAt.Format(dateLayout), g.Winner.Name, g.Second.Name, strings.Join(names, ", ")),
+		}
+		if g.CreatedBy != "" {
+			entry.Author = &atomAuthor{Name: g.CreatedBy}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	return feed
+}
+
+func writeAtom(w http.ResponseWriter, feed atomFeed) {
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	_, _ = w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		slog.Error("could not write feed", "error", err)
+	}
+}
+
+func (a *App) handleFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	games, err := a.store.ListGames(feedLength)
+	if err != nil {
+		http.Error(w, "failed to load games", http.StatusInternalServerError)
+		return
+	}
+	anchors, err := a.store.SessionAnchors(games)
+	if err != nil {
+		http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+	writeAtom(w, newAtomFeed(baseURL(r), "Hest", "/feed.atom", "/games", games, anchors))
+}
+
+func (a *App) handlePlayerFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, err := parsePlayer(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "invalid player id", http.StatusBadRequest)
+		return
+	}
+
+	players, err := a.store.PlayersByIDs([]int{playerID})
+	if err != nil {
+		http.Error(w, "failed to load player", http.StatusInternalServerError)
+		return
+	}
+	if len(players) == 0 {
+		http.Error(w, "player not found", http.StatusNotFound)
+		return
+	}
+	player := players[0]
+
+	games, err := a.store.PlayerGames(playerID, feedLength)
+	if err != nil {
+		http.Error(w, "failed to load games", http.StatusInternalServerError)
+		return
+	}
+	anchors, err := a.store.SessionAnchors(games)
+	if err != nil {
+		http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+
+	id := strconv.Itoa(playerID)
+	writeAtom(w, newAtomFeed(baseURL(r), "Hest: "+player.Name, "/player/feed.atom?id="+id, "/player?id="+id, games, anchors))
+}
@@ -2,13 +2,30 @@ package main
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
 )
 
+// numberedGame is a game with its running number in the full game log.
+type numberedGame struct {
+	Game
+	Number int
+}
+
+type gameSession struct {
+	Session db.Session
+	Games   []numberedGame
+}
+
 type gamesView struct {
 	Path       string
 	Title      string
 	Games      []Game
+	Sessions   []gameSession
 	TotalGames int
+	Heatmap    heatmap
+	FieldSizes []db.FieldSizeMonth
 }
 
 func newGameView() gamesView {
@@ -18,9 +35,37 @@ func newGameView() gamesView {
 	}
 }
 
+// withGames adds games grouped by session, keeping the newest first order.
 func (g gamesView) withGames(games []Game) gamesView {
 	g.Games = games
 	g.TotalGames = len(games)
+
+	numbers := make(map[int]int, len(games))
+	dbGames := make([]db.Game, len(games))
+	for i, game := range games {
+		numbers[game.ID] = len(games) - i
+		dbGames[i] = db.Game(game)
+	}
+
+	sessions := db.GroupSessions(dbGames)
+	g.Sessions = make([]gameSession, len(sessions))
+	for i, s := range sessions {
+		rows := make([]numberedGame, len(s.Games))
+		for j, game := range s.Games {
+			rows[j] = numberedGame{Game: Game(game), Number: numbers[game.ID]}
+		}
+		g.Sessions[i] = gameSession{Session: s, Games: rows}
+	}
+	return g
+}
+
+func (g gamesView) withActivity(days []db.ActivityDay) gamesView {
+	g.Heatmap = newHeatmap(days, time.Now())
+	return g
+}
+
+func (g gamesView) withFieldSizes(months []db.FieldSizeMonth) gamesView {
+	g.FieldSizes = months
 	return g
 }
 
@@ -36,6 +81,21 @@ func (a *App) handleGames(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page := newGameView().withGames(games)
-	renderTemplate(w, "layout", page, "templates/layout.html", "templates/games.html")
+	days, err := a.store.ActivityDays()
+	if err != nil {
+		http.Error(w, "failed to load activity", http.StatusInternalServerError)
+		return
+	}
+
+	fieldSizes, err := a.store.FieldSizeByMonth()
+	if err != nil {
+		http.Error(w, "failed to load field sizes", http.StatusInternalServerError)
+		return
+	}
+
+	page := newGameView().
+		withGames(games).
+		withActivity(days).
+		withFieldSizes(fieldSizes)
+	renderTemplate(w, "layout", page, "templates/layout.html", "templates/games.html", "templates/heatmap.html", "templates/export.html")
 }
@@ -74,3 +74,32 @@ func (a *App) handleH2H(w http.ResponseWriter, r *http.Request) {
 	// Otherwise, render the full page
 	renderTemplate(w, "layout", view, "templates/layout.html", "templates/h2h.html")
 }
+
+type h2hMatrixView struct {
+	Path   string
+	Title  string
+	Matrix db.H2HMatrix
+}
+
+func newH2HMatrixView(matrix db.H2HMatrix) h2hMatrixView {
+	return h2hMatrixView{
+		Path:   "/h2h",
+		Title:  "H2H matrix",
+		Matrix: matrix,
+	}
+}
+
+func (a *App) handleH2HMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	matrix, err := a.store.GetH2HMatrix()
+	if err != nil {
+		http.Error(w, "loading h2h matrix", http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, "layout", newH2HMatrixView(matrix), "templates/layout.html", "templates/h2h_matrix.html")
+}
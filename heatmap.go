@@ -0,0 +1,59 @@
+package main
+
+import (
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// heatmapWeeks is the number of week columns shown in a calendar heatmap.
+const heatmapWeeks = 53
+
+var weekdayNames = [...]string{"søndag", "mandag", "tirsdag", "onsdag", "torsdag", "fredag", "lørdag"}
+
+type heatmapDay struct {
+	Date   time.Time
+	Games  int
+	Level  int
+	Future bool
+}
+
+// heatmap is a GitHub style calendar of games played per day. Days start on
+// a Monday and are laid out week by week.
+type heatmap struct {
+	Days  []heatmapDay
+	Total int
+}
+
+// newHeatmap builds a heatmap of the last heatmapWeeks weeks up to and
+// including end. Each day gets a level from 0 to 4 relative to the busiest
+// day in the period.
+func newHeatmap(days []db.ActivityDay, end time.Time) heatmap {
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(end.Weekday()) + 6) % 7 // days since Monday
+	start := end.AddDate(0, 0, -offset-(heatmapWeeks-1)*7)
+
+	counts := make(map[string]int, len(days))
+	for _, d := range days {
+		counts[d.Date.Format(dateLayout)] = d.Games
+	}
+
+	var h heatmap
+	busiest := 0
+	for d := start; len(h.Days) < heatmapWeeks*7; d = d.AddDate(0, 0, 1) {
+		day := heatmapDay{Date: d, Future: d.After(end)}
+		if !day.Future {
+			day.Games = counts[d.Format(dateLayout)]
+		}
+		h.Total += day.Games
+		busiest = max(busiest, day.Games)
+		h.Days = append(h.Days, day)
+	}
+
+	for i, d := range h.Days {
+		if d.Games > 0 {
+			h.Days[i].Level = (d.Games*4 + busiest - 1) / busiest
+		}
+	}
+	return h
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// maxImportSize caps the size of an uploaded CSV file.
+const maxImportSize = 10 << 20
+
+// importColumns are the columns an import file must have, in any order.
+// Other columns, such as created_by in the games export, are ignored.
+var importColumns = []string{"date", "participants", "winner", "second"}
+
+// parseImportCSV reads games from CSV with a header row. Participants are
+// separated by semicolons or commas. Rows that can't be read are returned as
+// errors with their line number, so the rest of the file can be previewed.
+func parseImportCSV(r io.Reader) ([]db.ImportGame, []db.ImportError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range importColumns {
+		if _, ok := index[name]; !ok {
+			return nil, nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var (
+		games   []db.ImportGame
+		rowErrs []db.ImportError
+		field   = func(record []string, name string) string {
+			if i := index[name]; i < len(record) {
+				// Undo the formula guard of our own export
+				value := strings.TrimSpace(record[i])
+				if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+					value = value[1:]
+				}
+				return value
+			}
+			return ""
+		}
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		playedAt, err := time.Parse(dateLayout, field(record, "date"))
+		if err != nil {
+			rowErrs = append(rowErrs, db.ImportError{Line: line, Message: "invalid date"})
+			continue
+		}
+
+		var participants []string
+		for _, name := range strings.FieldsFunc(field(record, "participants"), func(r rune) bool {
+			return r == ';' || r == ','
+		}) {
+			if name = strings.TrimSpace(name); name != "" {
+				participants = append(participants, name)
+			}
+		}
+
+		games = append(games, db.ImportGame{
+			Line:         line,
+			PlayedAt:     playedAt,
+			Participants: participants,
+			Winner:       field(record, "winner"),
+			Second:       field(record, "second"),
+		})
+	}
+	return games, rowErrs, nil
+}
+
+type importView struct {
+	Path     string
+	Title    string
+	CSV      string
+	Plan     *db.ImportPlan
+	Imported *db.ImportPlan
+	Error    string
+}
+
+func newImportView() importView {
+	return importView{
+		Path:  "/import",
+		Title: "Importér",
+	}
+}
+
+func (v importView) withCSV(raw string) importView {
+	v.CSV = raw
+	return v
+}
+
+func (v importView) withPlan(plan db.ImportPlan) importView {
+	v.Plan = &plan
+	return v
+}
+
+func (v importView) withImported(plan db.ImportPlan) importView {
+	v.Imported = &plan
+	v.CSV = ""
+	return v
+}
+
+func (v importView) withError(msg string) importView {
+	v.Error = msg
+	return v
+}
+
+func (a *App) handleImport(w http.ResponseWriter, r *http.Request) {
+	view := newImportView()
+	if r.Method == http.MethodGet {
+		renderTemplate(w, "layout", view, "templates/layout.html", "templates/import.html")
+		return
+	}
+
+	username, ok := ensureAuthAndForm(w, r)
+	if !ok {
+		return
+	}
+
+	// ParseForm in ensureAuthAndForm skips multipart bodies
+	if err := r.ParseMultipartForm(maxImportSize); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		http.Error(w, "bad form", http.StatusBadRequest)
+		return
+	}
+
+	// An uploaded file takes precedence over the pasted text
+	raw := r.FormValue("csv")
+	if file, _, err := r.FormFile("file"); err == nil {
+		defer file.Close()
+		b, err := io.ReadAll(io.LimitReader(file, maxImportSize))
+		if err != nil {
+			http.Error(w, "could not read file", http.StatusBadRequest)
+			return
+		}
+		raw = string(b)
+	}
+	view = view.withCSV(raw)
+
+	if strings.TrimSpace(raw) == "" {
+		view = view.withError("Vælg en fil eller indsæt CSV.")
+		renderTemplate(w, "layout", view, "templates/layout.html", "templates/import.html")
+		return
+	}
+
+	games, rowErrs, err := parseImportCSV(strings.NewReader(raw))
+	if err != nil {
+		view = view.withError("Kunne ikke læse CSV: " + err.Error())
+		renderTemplate(w, "layout", view, "templates/layout.html", "templates/import.html")
+		return
+	}
+
+	plan, err := a.store.PlanImport(games)
+	if err != nil {
+		http.Error(w, "failed to plan import", http.StatusInternalServerError)
+		return
+	}
+	plan.Errors = append(rowErrs, plan.Errors...)
+	view = view.withPlan(plan)
+
+	if r.FormValue("action") == "import" && len(plan.Errors) == 0 {
+		imported, err := a.store.Import(games, username)
+		if err != nil {
+			slog.Error("could not import games", "error", err)
+			view = view.withError("Importen fejlede, intet blev gemt.")
+		} else {
+			view = view.withImported(imported)
+		}
+	}
+
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/import.html")
+}
+
+// runImport is the import command. It prints the plan and imports it unless
+// -dry-run is given.
+func runImport(store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "print what would be imported without saving anything")
+	user := fs.String("user", "import", "name recorded as the creator of the games")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: hest import [-dry-run] [-user name] file.csv")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("expected a single CSV file")
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	games, rowErrs, err := parseImportCSV(file)
+	if err != nil {
+		return err
+	}
+	plan, err := store.PlanImport(games)
+	if err != nil {
+		return err
+	}
+	plan.Errors = append(rowErrs, plan.Errors...)
+	printImportPlan(os.Stdout, plan)
+
+	if len(plan.Errors) > 0 {
+		return fmt.Errorf("%d rows can't be imported, nothing was saved", len(plan.Errors))
+	}
+	if *dryRun {
+		return nil
+	}
+	if _, err := store.Import(games, *user); err != nil {
+		return err
+	}
+	fmt.Printf("imported %d games and %d new players\n", len(plan.Games), len(plan.NewPlayers))
+	return nil
+}
+
+func printImportPlan(w io.Writer, plan db.ImportPlan) {
+	fmt.Fprintf(w, "new players (%d):\n", len(plan.NewPlayers))
+	for _, name := range plan.NewPlayers {
+		fmt.Fprintf(w, "  + %s\n", name)
+	}
+	fmt.Fprintf(w, "games to add (%d):\n", len(plan.Games))
+	for _, g := range plan.Games {
+		fmt.Fprintf(w, "  + line %d: %s winner %s, 2nd %s, players %s\n",
+			g.Line, g.PlayedAt.Format(dateLayout), g.Winner, g.Second, strings.Join(g.Participants, ";"))
+	}
+	fmt.Fprintf(w, "already recorded (%d):\n", len(plan.Duplicates))
+	for _, g := range plan.Duplicates {
+		fmt.Fprintf(w, "  = line %d: %s winner %s, 2nd %s\n",
+			g.Line, g.PlayedAt.Format(dateLayout), g.Winner, g.Second)
+	}
+	if len(plan.Errors) > 0 {
+		fmt.Fprintf(w, "errors (%d):\n", len(plan.Errors))
+		for _, e := range plan.Errors {
+			fmt.Fprintf(w, "  ! line %d: %s\n", e.Line, e.Message)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestImportReadsGuardedExport checks that names guarded by csvText in an
+// export read back as they were.
+func TestImportReadsGuardedExport(t *testing.T) {
+	tests := []struct {
+		name     string
+		guarded  string
+		imported string
+	}{
+		{name: "plain", guarded: "Anna", imported: "Anna"},
+		{name: "formula", guarded: "'=SUM(A1)", imported: "=SUM(A1)"},
+		{name: "plus", guarded: "'+45", imported: "+45"},
+		{name: "minus", guarded: "'-Bo-", imported: "-Bo-"},
+		{name: "at", guarded: "'@Carl", imported: "@Carl"},
+		{name: "quote", guarded: "'Dora", imported: "'Dora"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvText(tt.imported); got != tt.guarded {
+				t.Fatalf("csvText(%q) = %q, want %q", tt.imported, got, tt.guarded)
+			}
+
+			var b strings.Builder
+			cw := csv.NewWriter(&b)
+			_ = cw.Write([]string{"date", "winner", "second", "participants"})
+			_ = cw.Write([]string{"2024-05-01", csvText(tt.imported), "Emil", csvText(tt.imported + participantSeparator + "Emil")})
+			cw.Flush()
+
+			games, rowErrs, err := parseImportCSV(strings.NewReader(b.String()))
+			if err != nil || len(rowErrs) > 0 {
+				t.Fatalf("parse: %v %v", err, rowErrs)
+			}
+			if len(games) != 1 {
+				t.Fatalf("got %d games, want 1", len(games))
+			}
+			g := games[0]
+			if g.Winner != tt.imported {
+				t.Errorf("winner %q, want %q", g.Winner, tt.imported)
+			}
+			if want := []string{tt.imported, "Emil"}; !reflect.DeepEqual(g.Participants, want) {
+				t.Errorf("participants %q, want %q", g.Participants, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+)
+
+// ActivityDay is the number of games played on a single calendar day.
+type ActivityDay struct {
+	Date  time.Time
+	Games int
+}
+
+// PlayerActivity describes how often a player shows up.
+type PlayerActivity struct {
+	// JoinedAt is the earliest of the player's creation date and their first
+	// game, since games may be recorded retroactively.
+	JoinedAt       time.Time
+	GamesPlayed    int
+	GamesHeld      int
+	AttendanceRate float64
+	GamesPerWeek   float64
+	BusiestWeekday time.Weekday
+}
+
+// PlayerActivity returns attendance statistics for a player. Attendance is
+// the share of all games held since the player joined that they took part in.
+func (s *Store) PlayerActivity(playerID int) (PlayerActivity, error) {
+	var (
+		activity PlayerActivity
+		joinedOn string
+	)
+	err := s.db.QueryRow(`
+WITH joined AS (
+	SELECT MIN(
+		COALESCE(date(p.created_at), date('now')),
+		COALESCE((
+			SELECT MIN(date(g.played_at))
+			FROM games g
+			JOIN game_players gp ON gp.game_id = g.id
+			WHERE gp.player_id = p.id
+		), date('now'))
+	) AS joined_on
+	FROM players p
+	WHERE p.id = ?
+)
+SELECT j.joined_on,
+	(SELECT COUNT(*) FROM game_players gp WHERE gp.player_id = ?) AS played,
+	(SELECT COUNT(*) FROM games g WHERE date(g.played_at) >= j.joined_on) AS held
+FROM joined j
+`, playerID, playerID).Scan(&joinedOn, &activity.GamesPlayed, &activity.GamesHeld)
+	if err != nil {
+		return activity, err
+	}
+
+	activity.JoinedAt, err = time.Parse(dayLayout, joinedOn)
+	if err != nil {
+		return activity, err
+	}
+	if activity.GamesHeld > 0 {
+		activity.AttendanceRate = float64(activity.GamesPlayed) / float64(activity.GamesHeld)
+	}
+
+	// Count at least one week so a new player doesn't get an inflated rate
+	weeks := time.Since(activity.JoinedAt).Hours() / 24 / 7
+	if weeks < 1 {
+		weeks = 1
+	}
+	activity.GamesPerWeek = float64(activity.GamesPlayed) / weeks
+
+	var weekday int
+	err = s.db.QueryRow(`
+SELECT CAST(strftime('%w', g.played_at) AS INTEGER) AS weekday
+FROM games g
+JOIN game_players gp ON gp.game_id = g.id
+WHERE gp.player_id = ?
+GROUP BY weekday
+ORDER BY COUNT(*) DESC, weekday ASC
+LIMIT 1
+`, playerID).Scan(&weekday)
+	if err != nil && err != sql.ErrNoRows {
+		return activity, err
+	}
+	activity.BusiestWeekday = time.Weekday(weekday)
+
+	return activity, nil
+}
+
+// PlayerActivityDays returns the number of games the player took part in for
+// each day they played, oldest first.
+func (s *Store) PlayerActivityDays(playerID int) ([]ActivityDay, error) {
+	rows, err := s.db.Query(`
+SELECT date(g.played_at) AS day, COUNT(*)
+FROM games g
+JOIN game_players gp ON gp.game_id = g.id
+WHERE gp.player_id = ?
+GROUP BY day
+ORDER BY day ASC
+`, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanActivityDays(rows)
+}
+
+// ActivityDays returns the number of games played by the whole group for each
+// day with games, oldest first.
+func (s *Store) ActivityDays() ([]ActivityDay, error) {
+	rows, err := s.db.Query(`
+SELECT date(played_at) AS day, COUNT(*)
+FROM games
+GROUP BY day
+ORDER BY day ASC
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanActivityDays(rows)
+}
+
+func scanActivityDays(rows *sql.Rows) ([]ActivityDay, error) {
+	var days []ActivityDay
+	for rows.Next() {
+		var (
+			day   ActivityDay
+			dateS string
+		)
+		if err := rows.Scan(&dateS, &day.Games); err != nil {
+			return nil, err
+		}
+		date, err := time.Parse(dayLayout, dateS)
+		if err != nil {
+			return nil, err
+		}
+		day.Date = date
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
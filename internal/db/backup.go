@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupStepPages is the number of pages copied per backup step. Writers
+// only wait for a single step, so the app keeps serving during a backup.
+const backupStepPages = 256
+
+// backupStepPause is how long to yield to other connections between steps.
+const backupStepPause = 10 * time.Millisecond
+
+// Backup writes a consistent snapshot of the database to path using SQLite's
+// online backup API. The snapshot is written next to path and renamed into
+// place when complete, so path never holds a partial backup.
+func (s *Store) Backup(ctx context.Context, path string) error {
+	tmp := path + ".tmp"
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := s.backupTo(ctx, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Store) backupTo(ctx context.Context, path string) error {
+	dest, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			backup, err := destDriver.(*sqlite3.SQLiteConn).Backup("main", srcDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+
+			for {
+				done, err := backup.Step(backupStepPages)
+				if err != nil {
+					backup.Close()
+					return err
+				}
+				if done {
+					return backup.Close()
+				}
+
+				select {
+				case <-ctx.Done():
+					backup.Close()
+					return ctx.Err()
+				case <-time.After(backupStepPause):
+				}
+			}
+		})
+	})
+}
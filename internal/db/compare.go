@@ -0,0 +1,110 @@
+package db
+
+import (
+	"sort"
+	"time"
+)
+
+// ComparisonSeries holds one player's history aligned on a Comparison's
+// dates. Values are nil before the player's first game and otherwise carry
+// the latest value at the end of each date forward.
+type ComparisonSeries struct {
+	Player Player
+	Points []*int
+	PPG    []*float64
+	Rank   []*int
+}
+
+// Comparison is the history of several players on a shared time axis.
+type Comparison struct {
+	Dates  []time.Time
+	Series []ComparisonSeries
+}
+
+// CompareHistory returns the game and rank history of the given players
+// aligned on the dates any of them has history for.
+func (s *Store) CompareHistory(playerIDs []int) (Comparison, error) {
+	var comparison Comparison
+
+	players, err := s.PlayersByIDs(Dedupe(playerIDs))
+	if err != nil {
+		return comparison, err
+	}
+
+	type dayValues struct {
+		points *int
+		ppg    *float64
+		rank   *int
+	}
+	byPlayer := make([]map[string]*dayValues, len(players))
+	days := make(map[string]time.Time)
+
+	valuesFor := func(values map[string]*dayValues, at time.Time) *dayValues {
+		day := at.Format(dayLayout)
+		if _, ok := days[day]; !ok {
+			days[day], _ = time.Parse(dayLayout, day)
+		}
+		if values[day] == nil {
+			values[day] = &dayValues{}
+		}
+		return values[day]
+	}
+
+	for i, p := range players {
+		games, err := s.PlayerGameHistory(p.ID)
+		if err != nil {
+			return comparison, err
+		}
+		ranks, err := s.PlayerRankHistory(p.ID)
+		if err != nil {
+			return comparison, err
+		}
+
+		// Both histories are in playing order, so the last entry of a day wins
+		values := make(map[string]*dayValues)
+		for _, g := range games {
+			v := valuesFor(values, g.PlayedAt)
+			points, ppg := g.TotalPoints, g.PPG
+			v.points, v.ppg = &points, &ppg
+		}
+		for _, r := range ranks {
+			v := valuesFor(values, r.PlayedAt)
+			rank := r.Rank
+			v.rank = &rank
+		}
+		byPlayer[i] = values
+	}
+
+	for _, d := range days {
+		comparison.Dates = append(comparison.Dates, d)
+	}
+	sort.Slice(comparison.Dates, func(i, j int) bool {
+		return comparison.Dates[i].Before(comparison.Dates[j])
+	})
+
+	for i, p := range players {
+		series := ComparisonSeries{
+			Player: p,
+			Points: make([]*int, len(comparison.Dates)),
+			PPG:    make([]*float64, len(comparison.Dates)),
+			Rank:   make([]*int, len(comparison.Dates)),
+		}
+		var last dayValues
+		for j, d := range comparison.Dates {
+			if v, ok := byPlayer[i][d.Format(dayLayout)]; ok {
+				if v.points != nil {
+					last.points, last.ppg = v.points, v.ppg
+				}
+				if v.rank != nil {
+					last.rank = v.rank
+				}
+			}
+			series.Points[j] = last.points
+			series.PPG[j] = last.ppg
+			series.Rank[j] = last.rank
+		}
+		comparison.Series = append(comparison.Series, series)
+	}
+
+	return comparison, nil
+}
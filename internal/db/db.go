@@ -2,11 +2,20 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	// ErrNotFound is returned when a requested record doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when a record with the same unique value
+	// already exists.
+	ErrDuplicate = errors.New("already exists")
 )
 
 type Store struct {
@@ -31,6 +40,7 @@ type Game struct {
 	Second       Player
 	Participants []Player
 	CreatedBy    string
+	SessionID    int
 }
 
 type PlayerGameHistoryEntry struct {
@@ -46,6 +56,16 @@ type PlayerRankHistoryEntry struct {
 	Rank     int
 }
 
+// RankHistoryEntry is a player's position on the leaderboard right after a
+// game was played.
+type RankHistoryEntry struct {
+	GameID   int
+	PlayedAt time.Time
+	PlayerID int
+	Rank     int
+	Points   int
+}
+
 type H2HStats struct {
 	Player1         Player
 	Player2         Player
@@ -53,6 +73,40 @@ type H2HStats struct {
 	Player1Stats    Player
 	Player2Stats    Player
 	SharedGamesList []Game
+
+	// Player1Ahead and Player2Ahead count the shared games where that player
+	// placed strictly ahead of the other. BothOffPodium counts the games
+	// where neither won nor came second, so the placement between them is
+	// unknown.
+	Player1Ahead  int
+	Player2Ahead  int
+	BothOffPodium int
+}
+
+// H2HRecord is one player's record against a single opponent in the games
+// they both took part in.
+type H2HRecord struct {
+	Games   int
+	Wins    int
+	Seconds int
+	Ahead   int
+	Points  int
+	PPG     float64
+}
+
+type H2HMatrixCell struct {
+	Opponent Player
+	Record   H2HRecord
+}
+
+type H2HMatrixRow struct {
+	Player Player
+	Cells  []H2HMatrixCell
+}
+
+type H2HMatrix struct {
+	Players []Player
+	Rows    []H2HMatrixRow
 }
 
 func Open(path string) (*Store, error) {
@@ -75,9 +129,23 @@ func ensureSchema(db *sql.DB) error {
 	if err := createTables(db); err != nil {
 		return err
 	}
+	if err := addColumn(db, "games", "session_id", "INTEGER"); err != nil {
+		return err
+	}
 	return nil
 }
 
+// addColumn adds a column to an existing table unless it is already there.
+func addColumn(db *sql.DB, table, column, definition string) error {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
 func createTables(db *sql.DB) error {
 	const schema = `
 CREATE TABLE IF NOT EXISTS players (
@@ -94,6 +162,7 @@ CREATE TABLE IF NOT EXISTS games (
 	second_id INTEGER NOT NULL REFERENCES players(id),
 	created_by TEXT,
 	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	session_id INTEGER,
 	CHECK (winner_id != second_id)
 );
 
@@ -124,6 +193,28 @@ func buildPlaceholders(ids []int) (string, []any) {
 	return strings.Join(placeholders, ","), args
 }
 
+// gameSelect selects the columns read by scanGame. Queries append their own
+// joins, filters and ordering.
+const gameSelect = `
+SELECT g.id, g.played_at,
+	g.winner_id, winner.name, winner.emoji,
+	g.second_id, second.name, second.emoji,
+	COALESCE(g.created_by, ''),
+	COALESCE(g.session_id, 0)
+FROM games g
+JOIN players winner ON winner.id = g.winner_id
+JOIN players second ON second.id = g.second_id`
+
+// scanGame scans a game row from a gameSelect query, without participants.
+func scanGame(scanner interface{ Scan(...any) error }) (Game, error) {
+	var g Game
+	err := scanner.Scan(&g.ID, &g.PlayedAt,
+		&g.Winner.ID, &g.Winner.Name, &g.Winner.Emoji,
+		&g.Second.ID, &g.Second.Name, &g.Second.Emoji,
+		&g.CreatedBy, &g.SessionID)
+	return g, err
+}
+
 // scanPlayer scans a player row from the playerTotalsQuery result.
 func scanPlayer(scanner interface{ Scan(...any) error }) (Player, error) {
 	var p Player
@@ -131,22 +222,52 @@ func scanPlayer(scanner interface{ Scan(...any) error }) (Player, error) {
 	return p, err
 }
 
-func (s *Store) AddPlayer(name string) error {
-	_, err := s.db.Exec(`INSERT INTO players (name, emoji) VALUES (?, ?)`, name, emoji(name))
-	return err
+// AddPlayer creates a player and returns their ID. It returns ErrDuplicate if
+// the name is taken.
+func (s *Store) AddPlayer(name string) (int, error) {
+	res, err := s.db.Exec(`INSERT INTO players (name, emoji) VALUES (?, ?)`, name, emoji(name))
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return 0, ErrDuplicate
+		}
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
 }
 
-func (s *Store) ListGames() ([]Game, error) {
-	rows, err := s.db.Query(`
-SELECT g.id, g.played_at,
-	g.winner_id, winner.name, winner.emoji,
-	g.second_id, second.name, second.emoji,
-	COALESCE(g.created_by, '')
-FROM games g
-JOIN players winner ON winner.id = g.winner_id
-JOIN players second ON second.id = g.second_id
+// GetGame returns a single game with its participants, or ErrNotFound.
+func (s *Store) GetGame(id int) (Game, error) {
+	g, err := scanGame(s.db.QueryRow(gameSelect+`
+WHERE g.id = ?
+`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return g, ErrNotFound
+	}
+	if err != nil {
+		return g, err
+	}
+
+	participantMap, err := s.loadGameParticipants([]int{id})
+	if err != nil {
+		return g, err
+	}
+	g.Participants = participantMap[id]
+	return g, nil
+}
+
+// ListGames returns games newest first. A limit of 0 returns every game.
+func (s *Store) ListGames(limit int) ([]Game, error) {
+	query := gameSelect + `
 ORDER BY g.played_at DESC, g.id DESC
-`)
+`
+	var args []any
+	if limit > 0 {
+		query += "LIMIT ?\n"
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -155,20 +276,10 @@ ORDER BY g.played_at DESC, g.id DESC
 	var games []Game
 	var gameIDs []int
 	for rows.Next() {
-		var (
-			g        Game
-			winnerID int
-			secondID int
-			wEmoji   string
-			sEmoji   string
-		)
-		if err := rows.Scan(&g.ID, &g.PlayedAt, &winnerID, &g.Winner.Name, &wEmoji, &secondID, &g.Second.Name, &sEmoji, &g.CreatedBy); err != nil {
+		g, err := scanGame(rows)
+		if err != nil {
 			return nil, err
 		}
-		g.Winner.ID = winnerID
-		g.Winner.Emoji = wEmoji
-		g.Second.ID = secondID
-		g.Second.Emoji = sEmoji
 		games = append(games, g)
 		gameIDs = append(gameIDs, g.ID)
 	}
@@ -263,54 +374,64 @@ ORDER BY played_at ASC, id ASC
 	return history, rows.Err()
 }
 
-func (s *Store) PlayerRankHistory(playerID int) ([]PlayerRankHistoryEntry, error) {
-	rows, err := s.db.Query(`
-WITH player_first_game AS (
-	-- Find the player's first game
-	SELECT MIN(g.played_at) as first_game_date
-	FROM games g
-	JOIN game_players gp ON g.id = gp.game_id
-	WHERE gp.player_id = ?
-),
-player_games AS (
-	-- Get ALL games from the player's first game onward
-	SELECT g.id, g.played_at
-	FROM games g
-	CROSS JOIN player_first_game pfg
-	WHERE g.played_at >= pfg.first_game_date
-	ORDER BY g.played_at ASC, g.id ASC
-),
+// rankedLeaderboardCTE replays the leaderboard after every game in the
+// snapshot_games CTE, which must be defined before it with id and played_at
+// columns. It ranks all players with the same tiebreakers as the leaderboard,
+// so players without games yet are ranked last.
+const rankedLeaderboardCTE = `
 leaderboard_snapshots AS (
 	-- For each game, calculate ALL players' stats up to that point
 	SELECT
-		pg.played_at,
-		pg.id as game_id,
+		sg.played_at,
+		sg.id as game_id,
 		p.id as player_id,
+		p.name as name,
 		COUNT(DISTINCT g_hist.id) as games,
 		COUNT(DISTINCT CASE WHEN g_hist.winner_id = p.id THEN g_hist.id END) as wins,
 		COUNT(DISTINCT CASE WHEN g_hist.second_id = p.id THEN g_hist.id END) as seconds,
 		(COUNT(DISTINCT CASE WHEN g_hist.winner_id = p.id THEN g_hist.id END) * 3 +
 		COUNT(DISTINCT CASE WHEN g_hist.second_id = p.id THEN g_hist.id END)) as points
-	FROM player_games pg
+	FROM snapshot_games sg
 	CROSS JOIN players p
 	LEFT JOIN game_players gp_hist ON gp_hist.player_id = p.id
 	LEFT JOIN games g_hist ON g_hist.id = gp_hist.game_id
-		AND (g_hist.played_at < pg.played_at
-			OR (g_hist.played_at = pg.played_at AND g_hist.id <= pg.id)
+		AND (g_hist.played_at < sg.played_at
+			OR (g_hist.played_at = sg.played_at AND g_hist.id <= sg.id)
 		)
-	GROUP BY pg.played_at, pg.id, p.id
+	GROUP BY sg.played_at, sg.id, p.id
 ),
 ranked_leaderboard AS (
 	-- Apply ranking with proper tiebreakers
 	SELECT
 		played_at,
+		game_id,
 		player_id,
+		games,
+		points,
 		ROW_NUMBER() OVER (
 			PARTITION BY played_at, game_id
-			ORDER BY points DESC, wins DESC, seconds DESC, games DESC, player_id ASC
+			ORDER BY points DESC, wins DESC, seconds DESC, games DESC, name ASC
 		) as rank
 	FROM leaderboard_snapshots
-)
+)`
+
+func (s *Store) PlayerRankHistory(playerID int) ([]PlayerRankHistoryEntry, error) {
+	rows, err := s.db.Query(`
+WITH player_first_game AS (
+	-- Find the player's first game
+	SELECT MIN(g.played_at) as first_game_date
+	FROM games g
+	JOIN game_players gp ON g.id = gp.game_id
+	WHERE gp.player_id = ?
+),
+snapshot_games AS (
+	-- Get ALL games from the player's first game onward
+	SELECT g.id, g.played_at
+	FROM games g
+	CROSS JOIN player_first_game pfg
+	WHERE g.played_at >= pfg.first_game_date
+	ORDER BY g.played_at ASC, g.id ASC
+),`+rankedLeaderboardCTE+`
 SELECT played_at, rank
 FROM ranked_leaderboard
 WHERE player_id = ?
@@ -333,19 +454,49 @@ ORDER BY played_at ASC
 	return history, rows.Err()
 }
 
-func (s *Store) PlayerGames(playerID int) ([]Game, error) {
+// RankHistory returns every player's leaderboard position after each game,
+// oldest game first. Players are only included from their first game.
+func (s *Store) RankHistory() ([]RankHistoryEntry, error) {
 	rows, err := s.db.Query(`
-SELECT g.id, g.played_at,
-	g.winner_id, winner.name, winner.emoji,
-	g.second_id, second.name, second.emoji,
-	COALESCE(g.created_by, '')
-FROM games g
-JOIN players winner ON winner.id = g.winner_id
-JOIN players second ON second.id = g.second_id
+WITH snapshot_games AS (
+	SELECT id, played_at
+	FROM games
+),` + rankedLeaderboardCTE + `
+SELECT game_id, played_at, player_id, rank, points
+FROM ranked_leaderboard
+WHERE games > 0
+ORDER BY played_at ASC, game_id ASC, rank ASC
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []RankHistoryEntry
+	for rows.Next() {
+		var entry RankHistoryEntry
+		if err := rows.Scan(&entry.GameID, &entry.PlayedAt, &entry.PlayerID, &entry.Rank, &entry.Points); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+// PlayerGames returns the games of a player newest first. A limit of 0
+// returns every game.
+func (s *Store) PlayerGames(playerID, limit int) ([]Game, error) {
+	query := gameSelect + `
 JOIN game_players gp ON g.id = gp.game_id
 WHERE gp.player_id = ?
 ORDER BY g.played_at DESC, g.id DESC
-`, playerID)
+`
+	args := []any{playerID}
+	if limit > 0 {
+		query += "LIMIT ?\n"
+		args = append(args, limit)
+	}
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -354,20 +505,10 @@ ORDER BY g.played_at DESC, g.id DESC
 	var games []Game
 	var gameIDs []int
 	for rows.Next() {
-		var (
-			g        Game
-			winnerID int
-			secondID int
-			wEmoji   string
-			sEmoji   string
-		)
-		if err := rows.Scan(&g.ID, &g.PlayedAt, &winnerID, &g.Winner.Name, &wEmoji, &secondID, &g.Second.Name, &sEmoji, &g.CreatedBy); err != nil {
+		g, err := scanGame(rows)
+		if err != nil {
 			return nil, err
 		}
-		g.Winner.ID = winnerID
-		g.Winner.Emoji = wEmoji
-		g.Second.ID = secondID
-		g.Second.Emoji = sEmoji
 		games = append(games, g)
 		gameIDs = append(gameIDs, g.ID)
 	}
@@ -390,6 +531,101 @@ ORDER BY g.played_at DESC, g.id DESC
 	return games, nil
 }
 
+// GamesOnThisDay returns the games played on the same calendar day as date in
+// earlier years, newest first.
+func (s *Store) GamesOnThisDay(date time.Time) ([]Game, error) {
+	rows, err := s.db.Query(gameSelect+`
+WHERE strftime('%m-%d', g.played_at) = ?
+	AND CAST(strftime('%Y', g.played_at) AS INTEGER) < ?
+ORDER BY g.played_at DESC, g.id DESC
+`, date.Format("01-02"), date.Year())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []Game
+	var gameIDs []int
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return nil, err
+		}
+		games = append(games, g)
+		gameIDs = append(gameIDs, g.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(games) > 0 {
+		participantMap, err := s.loadGameParticipants(gameIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, g := range games {
+			g.Participants = participantMap[g.ID]
+			games[i] = g
+		}
+	}
+
+	return games, nil
+}
+
+// FormResult is a player's placement in one of their recent games. Place is 1
+// for a win, 2 for second place and 0 otherwise.
+type FormResult struct {
+	GameID   int
+	PlayedAt time.Time
+	Place    int
+}
+
+// RecentForm returns the last n results of every player who has played,
+// most recent first, keyed by player ID.
+func (s *Store) RecentForm(n int) (map[int][]FormResult, error) {
+	rows, err := s.db.Query(`
+WITH ranked AS (
+	SELECT
+		gp.player_id,
+		g.id,
+		g.played_at,
+		CASE
+			WHEN g.winner_id = gp.player_id THEN 1
+			WHEN g.second_id = gp.player_id THEN 2
+			ELSE 0
+		END AS place,
+		ROW_NUMBER() OVER (
+			PARTITION BY gp.player_id
+			ORDER BY g.played_at DESC, g.id DESC
+		) AS recency
+	FROM game_players gp
+	JOIN games g ON g.id = gp.game_id
+)
+SELECT player_id, id, played_at, place
+FROM ranked
+WHERE recency <= ?
+ORDER BY player_id, recency
+`, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	form := make(map[int][]FormResult)
+	for rows.Next() {
+		var (
+			playerID int
+			result   FormResult
+		)
+		if err := rows.Scan(&playerID, &result.GameID, &result.PlayedAt, &result.Place); err != nil {
+			return nil, err
+		}
+		form[playerID] = append(form[playerID], result)
+	}
+	return form, rows.Err()
+}
+
 func (s *Store) ListPlayersByName() ([]Player, error) {
 	rows, err := s.db.Query(playerTotalsQuery("", `ORDER BY name ASC`))
 	if err != nil {
@@ -430,6 +666,25 @@ ORDER BY points DESC, wins DESC, seconds DESC, games DESC, name ASC
 	return players, rows.Err()
 }
 
+// ranksAbove reports whether a is ahead of b with the leaderboard tiebreakers:
+// points, wins, seconds and games, and a full tie goes to the name that sorts
+// first. Every ranking, in SQL or Go, orders players this way.
+func ranksAbove(a, b Player) bool {
+	if a.Points != b.Points {
+		return a.Points > b.Points
+	}
+	if a.Wins != b.Wins {
+		return a.Wins > b.Wins
+	}
+	if a.Seconds != b.Seconds {
+		return a.Seconds > b.Seconds
+	}
+	if a.Games != b.Games {
+		return a.Games > b.Games
+	}
+	return a.Name < b.Name
+}
+
 func (s *Store) PlayersByIDs(ids []int) ([]Player, error) {
 	if len(ids) == 0 {
 		return nil, nil
@@ -478,17 +733,28 @@ func Dedupe(ids []int) []int {
 	return result
 }
 
-// validateGameParticipants checks that the game has valid participants and placements.
-func validateGameParticipants(participantIDs []int, winnerID, secondID int) error {
+// validateGame checks that the game has a date, distinct participants and
+// placements held by two different participants. Games are written with these
+// rules whether they are added, imported or restored.
+func validateGame(playedAt time.Time, participantIDs []int, winnerID, secondID int) error {
+	if playedAt.IsZero() {
+		return fmt.Errorf("missing date")
+	}
 	if len(participantIDs) == 0 {
 		return fmt.Errorf("no participants")
 	}
 
 	participantSet := make(map[int]struct{}, len(participantIDs))
 	for _, id := range participantIDs {
+		if _, ok := participantSet[id]; ok {
+			return fmt.Errorf("player %d takes part more than once", id)
+		}
 		participantSet[id] = struct{}{}
 	}
 
+	if winnerID == secondID {
+		return fmt.Errorf("winner and second place must be different players")
+	}
 	if _, ok := participantSet[winnerID]; !ok {
 		return fmt.Errorf("winner must be a participant")
 	}
@@ -498,46 +764,58 @@ func validateGameParticipants(participantIDs []int, winnerID, secondID int) erro
 	return nil
 }
 
-func (s *Store) AddGame(playedAt time.Time, participantIDs []int, winnerID, secondID int, createdBy string) error {
-	uniqueIDs := Dedupe(participantIDs)
-	if err := validateGameParticipants(uniqueIDs, winnerID, secondID); err != nil {
-		return err
+// AddGame records a game and returns its ID. A non-zero sessionID explicitly
+// places the game in that session, otherwise games are grouped into sessions
+// by date.
+func (s *Store) AddGame(playedAt time.Time, participantIDs []int, winnerID, secondID int, createdBy string, sessionID int) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
 	}
 
-	tx, err := s.db.Begin()
+	gameID, err := addGame(tx, playedAt, participantIDs, winnerID, secondID, createdBy, sessionID)
 	if err != nil {
-		return err
+		tx.Rollback()
+		return 0, err
+	}
+	return gameID, tx.Commit()
+}
+
+// addGame inserts a game within tx so callers can record several games
+// atomically.
+func addGame(tx *sql.Tx, playedAt time.Time, participantIDs []int, winnerID, secondID int, createdBy string, sessionID int) (int, error) {
+	uniqueIDs := Dedupe(participantIDs)
+	if err := validateGame(playedAt, uniqueIDs, winnerID, secondID); err != nil {
+		return 0, err
 	}
-	defer func() {
-		if err != nil {
-			tx.Rollback()
-		}
-	}()
 
-	res, err := tx.Exec(`INSERT INTO games (played_at, winner_id, second_id, created_by) VALUES (?, ?, ?, ?)`, playedAt, winnerID, secondID, createdBy)
+	var session sql.NullInt64
+	if sessionID != 0 {
+		session = sql.NullInt64{Int64: int64(sessionID), Valid: true}
+	}
+
+	res, err := tx.Exec(`INSERT INTO games (played_at, winner_id, second_id, created_by, session_id) VALUES (?, ?, ?, ?, ?)`, playedAt, winnerID, secondID, createdBy, session)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	gameID, err := res.LastInsertId()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	stmt, err := tx.Prepare(`INSERT INTO game_players (game_id, player_id) VALUES (?, ?)`)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer stmt.Close()
 
 	for _, pid := range uniqueIDs {
 		if _, err = stmt.Exec(gameID, pid); err != nil {
-			return err
+			return 0, err
 		}
 	}
-
-	err = tx.Commit()
-	return err
+	return int(gameID), nil
 }
 
 // GetH2HStats returns head-to-head statistics for two players, including only games where both participated.
@@ -556,14 +834,7 @@ func (s *Store) GetH2HStats(player1ID, player2ID int) (H2HStats, error) {
 	stats.Player2 = players[1]
 
 	// Get games where both players participated
-	rows, err := s.db.Query(`
-SELECT g.id, g.played_at,
-	g.winner_id, winner.name, winner.emoji,
-	g.second_id, second.name, second.emoji,
-	COALESCE(g.created_by, '')
-FROM games g
-JOIN players winner ON winner.id = g.winner_id
-JOIN players second ON second.id = g.second_id
+	rows, err := s.db.Query(gameSelect+`
 JOIN game_players gp1 ON g.id = gp1.game_id AND gp1.player_id = ?
 JOIN game_players gp2 ON g.id = gp2.game_id AND gp2.player_id = ?
 ORDER BY g.played_at DESC, g.id DESC
@@ -576,20 +847,10 @@ ORDER BY g.played_at DESC, g.id DESC
 	var games []Game
 	var gameIDs []int
 	for rows.Next() {
-		var (
-			g        Game
-			winnerID int
-			secondID int
-			wEmoji   string
-			sEmoji   string
-		)
-		if err := rows.Scan(&g.ID, &g.PlayedAt, &winnerID, &g.Winner.Name, &wEmoji, &secondID, &g.Second.Name, &sEmoji, &g.CreatedBy); err != nil {
+		g, err := scanGame(rows)
+		if err != nil {
 			return stats, err
 		}
-		g.Winner.ID = winnerID
-		g.Winner.Emoji = wEmoji
-		g.Second.ID = secondID
-		g.Second.Emoji = sEmoji
 		games = append(games, g)
 		gameIDs = append(gameIDs, g.ID)
 	}
@@ -665,9 +926,117 @@ ORDER BY g.played_at DESC, g.id DESC
 		PPG:     p2PPG,
 	}
 
+	// Compare placements between the two players in shared games
+	for _, g := range games {
+		switch {
+		case finishedAhead(player1ID, player2ID, g.Winner.ID, g.Second.ID):
+			stats.Player1Ahead++
+		case finishedAhead(player2ID, player1ID, g.Winner.ID, g.Second.ID):
+			stats.Player2Ahead++
+		default:
+			stats.BothOffPodium++
+		}
+	}
+
 	return stats, nil
 }
 
+// finishedAhead reports whether playerID placed strictly ahead of opponentID
+// in a game. The winner beats everyone and second place beats everyone except
+// the winner.
+func finishedAhead(playerID, opponentID, winnerID, secondID int) bool {
+	if playerID == winnerID {
+		return true
+	}
+	return playerID == secondID && opponentID != winnerID
+}
+
+// GetH2HMatrix returns every player's record against every other player in
+// their shared games. Rows and columns follow the leaderboard order. All
+// games are read in a single pass instead of calling GetH2HStats per pair.
+func (s *Store) GetH2HMatrix() (H2HMatrix, error) {
+	var matrix H2HMatrix
+
+	players, err := s.ListPlayersByPoints()
+	if err != nil {
+		return matrix, err
+	}
+	matrix.Players = players
+
+	rows, err := s.db.Query(`
+SELECT gp.game_id, gp.player_id, g.winner_id, g.second_id
+FROM game_players gp
+JOIN games g ON g.id = gp.game_id
+ORDER BY gp.game_id
+`)
+	if err != nil {
+		return matrix, err
+	}
+	defer rows.Close()
+
+	type pair struct{ player, opponent int }
+	records := make(map[pair]H2HRecord)
+
+	tally := func(participants []int, winnerID, secondID int) {
+		for _, p := range participants {
+			for _, o := range participants {
+				if p == o {
+					continue
+				}
+				r := records[pair{p, o}]
+				r.Games++
+				if p == winnerID {
+					r.Wins++
+				} else if p == secondID {
+					r.Seconds++
+				}
+				if finishedAhead(p, o, winnerID, secondID) {
+					r.Ahead++
+				}
+				records[pair{p, o}] = r
+			}
+		}
+	}
+
+	var (
+		currentGame        int
+		winnerID, secondID int
+		participants       []int
+	)
+	for rows.Next() {
+		var gameID, playerID, gameWinner, gameSecond int
+		if err := rows.Scan(&gameID, &playerID, &gameWinner, &gameSecond); err != nil {
+			return matrix, err
+		}
+		if gameID != currentGame {
+			tally(participants, winnerID, secondID)
+			currentGame, winnerID, secondID = gameID, gameWinner, gameSecond
+			participants = participants[:0]
+		}
+		participants = append(participants, playerID)
+	}
+	if err := rows.Err(); err != nil {
+		return matrix, err
+	}
+	tally(participants, winnerID, secondID)
+
+	matrix.Rows = make([]H2HMatrixRow, len(players))
+	for i, p := range players {
+		row := H2HMatrixRow{Player: p, Cells: make([]H2HMatrixCell, len(players))}
+		for j, o := range players {
+			r := records[pair{p.ID, o.ID}]
+			r.Points = r.Wins*3 + r.Seconds
+			if r.Games > 0 {
+				r.PPG = float64(r.Points) / float64(r.Games)
+			}
+			row.Cells[j] = H2HMatrixCell{Opponent: o, Record: r}
+		}
+		matrix.Rows[i] = row
+	}
+
+	return matrix, nil
+}
+
 // playerTotalsQuery builds a query to fetch player statistics.
 // The where parameter should include the WHERE keyword if needed (e.g., "WHERE p.id IN (...)").
 // The order parameter should include the ORDER BY keyword if needed.
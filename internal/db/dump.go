@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DumpVersion is the version of the dump format written by Dump. Restore only
+// accepts dumps of this version.
+const DumpVersion = 1
+
+// ErrNotEmpty is returned when restoring into a database that has data.
+var ErrNotEmpty = errors.New("database is not empty")
+
+// Dump is a portable copy of the whole database.
+type Dump struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Players   []DumpPlayer `json:"players"`
+	Games     []DumpGame   `json:"games"`
+}
+
+type DumpPlayer struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Emoji     string     `json:"emoji"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+type DumpGame struct {
+	ID           int        `json:"id"`
+	PlayedAt     time.Time  `json:"played_at"`
+	WinnerID     int        `json:"winner_id"`
+	SecondID     int        `json:"second_id"`
+	Participants []int      `json:"participants"`
+	CreatedBy    string     `json:"created_by,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	SessionID    int        `json:"session_id,omitempty"`
+}
+
+// Dump reads all players and games. The reads share one read-only
+// transaction, so a game saved meanwhile is either in the dump with all its
+// participants or not at all.
+func (s *Store) Dump(ctx context.Context, now time.Time) (Dump, error) {
+	dump := Dump{
+		Version:   DumpVersion,
+		CreatedAt: now,
+		Players:   []DumpPlayer{},
+		Games:     []DumpGame{},
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return dump, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, name, COALESCE(emoji, ''), created_at FROM players ORDER BY id`)
+	if err != nil {
+		return dump, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			p         DumpPlayer
+			createdAt sql.NullTime
+		)
+		if err := rows.Scan(&p.ID, &p.Name, &p.Emoji, &createdAt); err != nil {
+			return dump, err
+		}
+		p.CreatedAt = nullTimePtr(createdAt)
+		dump.Players = append(dump.Players, p)
+	}
+	if err := rows.Err(); err != nil {
+		return dump, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+SELECT id, played_at, winner_id, second_id, COALESCE(created_by, ''), created_at, COALESCE(session_id, 0)
+FROM games
+ORDER BY id
+`)
+	if err != nil {
+		return dump, err
+	}
+	defer rows.Close()
+	index := make(map[int]int)
+	for rows.Next() {
+		var (
+			g         DumpGame
+			createdAt sql.NullTime
+		)
+		if err := rows.Scan(&g.ID, &g.PlayedAt, &g.WinnerID, &g.SecondID, &g.CreatedBy, &createdAt, &g.SessionID); err != nil {
+			return dump, err
+		}
+		g.CreatedAt = nullTimePtr(createdAt)
+		g.Participants = []int{}
+		index[g.ID] = len(dump.Games)
+		dump.Games = append(dump.Games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return dump, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `SELECT game_id, player_id FROM game_players ORDER BY game_id, player_id`)
+	if err != nil {
+		return dump, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var gameID, playerID int
+		if err := rows.Scan(&gameID, &playerID); err != nil {
+			return dump, err
+		}
+		if i, ok := index[gameID]; ok {
+			dump.Games[i].Participants = append(dump.Games[i].Participants, playerID)
+		}
+	}
+	return dump, rows.Err()
+}
+
+// ValidateDump checks a dump before it's restored: the version, that IDs and
+// names are unique, and that every game refers to existing players and passes
+// validateGame like a newly added game.
+func ValidateDump(d Dump) error {
+	if d.Version != DumpVersion {
+		return fmt.Errorf("unsupported dump version %d, expected %d", d.Version, DumpVersion)
+	}
+
+	players := make(map[int]bool, len(d.Players))
+	names := make(map[string]bool, len(d.Players))
+	for _, p := range d.Players {
+		switch {
+		case p.ID <= 0:
+			return fmt.Errorf("player %q: invalid id %d", p.Name, p.ID)
+		case p.Name == "":
+			return fmt.Errorf("player %d: missing name", p.ID)
+		case players[p.ID]:
+			return fmt.Errorf("player %d: duplicate id", p.ID)
+		case names[p.Name]:
+			return fmt.Errorf("player %d: duplicate name %q", p.ID, p.Name)
+		}
+		players[p.ID] = true
+		names[p.Name] = true
+	}
+
+	games := make(map[int]bool, len(d.Games))
+	for _, g := range d.Games {
+		if g.ID <= 0 {
+			return fmt.Errorf("game with invalid id %d", g.ID)
+		}
+		if games[g.ID] {
+			return fmt.Errorf("game %d: duplicate id", g.ID)
+		}
+		games[g.ID] = true
+
+		for _, id := range g.Participants {
+			if !players[id] {
+				return fmt.Errorf("game %d: unknown player %d", g.ID, id)
+			}
+		}
+		if err := validateGame(g.PlayedAt, g.Participants, g.WinnerID, g.SecondID); err != nil {
+			return fmt.Errorf("game %d: %w", g.ID, err)
+		}
+	}
+	return nil
+}
+
+// Restore writes a dump into an empty database, keeping the IDs so links stay
+// valid. The dump is validated before anything is written.
+func (s *Store) Restore(d Dump) error {
+	if err := ValidateDump(d); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRow(`SELECT (SELECT COUNT(*) FROM players) + (SELECT COUNT(*) FROM games)`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrNotEmpty
+	}
+
+	for _, p := range d.Players {
+		createdAt := time.Now().UTC()
+		if p.CreatedAt != nil {
+			createdAt = *p.CreatedAt
+		}
+		if _, err := tx.Exec(`INSERT INTO players (id, name, emoji, created_at) VALUES (?, ?, ?, ?)`,
+			p.ID, p.Name, p.Emoji, createdAt); err != nil {
+			return fmt.Errorf("player %d: %w", p.ID, err)
+		}
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO game_players (game_id, player_id) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, g := range d.Games {
+		var (
+			createdBy sql.NullString
+			createdAt = time.Now().UTC()
+			session   sql.NullInt64
+		)
+		if g.CreatedBy != "" {
+			createdBy = sql.NullString{String: g.CreatedBy, Valid: true}
+		}
+		if g.CreatedAt != nil {
+			createdAt = *g.CreatedAt
+		}
+		if g.SessionID != 0 {
+			session = sql.NullInt64{Int64: int64(g.SessionID), Valid: true}
+		}
+		if _, err := tx.Exec(`INSERT INTO games (id, played_at, winner_id, second_id, created_by, created_at, session_id) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			g.ID, g.PlayedAt, g.WinnerID, g.SecondID, createdBy, createdAt, session); err != nil {
+			return fmt.Errorf("game %d: %w", g.ID, err)
+		}
+		for _, pid := range g.Participants {
+			if _, err := stmt.Exec(g.ID, pid); err != nil {
+				return fmt.Errorf("game %d: %w", g.ID, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"strings"
+	"time"
+)
+
+// exportBatch is the number of games read before their participants are
+// loaded, which keeps memory bounded when streaming the whole game log.
+const exportBatch = 100
+
+// DateRange limits games to those played between From and To, both
+// inclusive. A zero bound leaves that end open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// where returns a SQL condition on the given date column, or "1" when the
+// range is open on both ends.
+func (r DateRange) where(column string) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if !r.From.IsZero() {
+		conds = append(conds, "date("+column+") >= ?")
+		args = append(args, r.From.Format(dayLayout))
+	}
+	if !r.To.IsZero() {
+		conds = append(conds, "date("+column+") <= ?")
+		args = append(args, r.To.Format(dayLayout))
+	}
+	if len(conds) == 0 {
+		return "1", nil
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// EachGame calls fn for every game in the range, oldest first, without
+// holding the full game log in memory. Iteration stops at the first error.
+func (s *Store) EachGame(r DateRange, fn func(Game) error) error {
+	where, args := r.where("g.played_at")
+	rows, err := s.db.Query(gameSelect+`
+WHERE `+where+`
+ORDER BY g.played_at ASC, g.id ASC
+`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	batch := make([]Game, 0, exportBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		ids := make([]int, len(batch))
+		for i, g := range batch {
+			ids[i] = g.ID
+		}
+		participantMap, err := s.loadGameParticipants(ids)
+		if err != nil {
+			return err
+		}
+		for _, g := range batch {
+			g.Participants = participantMap[g.ID]
+			if err := fn(g); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		g, err := scanGame(rows)
+		if err != nil {
+			return err
+		}
+		batch = append(batch, g)
+		if len(batch) == exportBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// EachStanding calls fn for every player in leaderboard order, counting only
+// games in the range. With an open range it matches ListPlayersByPoints.
+func (s *Store) EachStanding(r DateRange, fn func(rank int, p Player) error) error {
+	where, args := r.where("played_at")
+	rows, err := s.db.Query(`
+WITH ranged AS (
+	SELECT gp.player_id, g.winner_id, g.second_id
+	FROM games g
+	JOIN game_players gp ON gp.game_id = g.id
+	WHERE `+where+`
+),
+totals AS (
+	SELECT p.id, p.name,
+		COALESCE(p.emoji, '') AS emoji,
+		COUNT(r.player_id) AS games,
+		COALESCE(SUM(r.winner_id = p.id), 0) AS wins,
+		COALESCE(SUM(r.second_id = p.id), 0) AS seconds
+	FROM players p
+	LEFT JOIN ranged r ON r.player_id = p.id
+	GROUP BY p.id
+)
+SELECT id, name, emoji, games, wins, seconds,
+	wins * 3 + seconds AS points,
+	CASE WHEN games = 0 THEN 0 ELSE CAST(wins * 3 + seconds AS REAL) / games END AS ppg
+FROM totals
+ORDER BY points DESC, wins DESC, seconds DESC, games DESC, name ASC
+`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	rank := 0
+	for rows.Next() {
+		p, err := scanPlayer(rows)
+		if err != nil {
+			return err
+		}
+		rank++
+		if err := fn(rank, p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
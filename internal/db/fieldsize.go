@@ -0,0 +1,115 @@
+package db
+
+import "time"
+
+// MaxFieldSize is the largest field size reported separately. Games with more
+// participants are counted in the same bucket.
+const MaxFieldSize = 5
+
+// FieldSizeStats is a player's results in games with a given number of
+// participants, compared to what pure chance would give.
+type FieldSizeStats struct {
+	FieldSize      int
+	Games          int
+	Wins           int
+	Seconds        int
+	WinRate        float64
+	PodiumRate     float64
+	WinBaseline    float64
+	PodiumBaseline float64
+}
+
+// FieldSizeMonth is the average number of participants per game in a month.
+type FieldSizeMonth struct {
+	Month      time.Time
+	Games      int
+	AvgPlayers float64
+	MaxPlayers int
+	MinPlayers int
+}
+
+// PlayerFieldSizeStats returns a player's win and podium rates broken down by
+// the number of participants, smallest fields first. The baselines are the
+// average 1/n and 2/n chance of winning and reaching the podium.
+func (s *Store) PlayerFieldSizeStats(playerID int) ([]FieldSizeStats, error) {
+	rows, err := s.db.Query(`
+WITH sizes AS (
+	SELECT game_id, COUNT(*) AS size
+	FROM game_players
+	GROUP BY game_id
+)
+SELECT
+	MIN(sz.size, ?) AS bucket,
+	COUNT(*) AS games,
+	SUM(g.winner_id = gp.player_id) AS wins,
+	SUM(g.second_id = gp.player_id) AS seconds,
+	AVG(1.0 / sz.size) AS win_baseline,
+	AVG(MIN(2.0 / sz.size, 1.0)) AS podium_baseline
+FROM game_players gp
+JOIN sizes sz ON sz.game_id = gp.game_id
+JOIN games g ON g.id = gp.game_id
+WHERE gp.player_id = ?
+GROUP BY bucket
+ORDER BY bucket ASC
+`, MaxFieldSize, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []FieldSizeStats
+	for rows.Next() {
+		var fs FieldSizeStats
+		if err := rows.Scan(&fs.FieldSize, &fs.Games, &fs.Wins, &fs.Seconds, &fs.WinBaseline, &fs.PodiumBaseline); err != nil {
+			return nil, err
+		}
+		fs.WinRate = float64(fs.Wins) / float64(fs.Games)
+		fs.PodiumRate = float64(fs.Wins+fs.Seconds) / float64(fs.Games)
+		stats = append(stats, fs)
+	}
+	return stats, rows.Err()
+}
+
+// FieldSizeByMonth returns the number of participants per game for each month
+// with games, oldest first.
+func (s *Store) FieldSizeByMonth() ([]FieldSizeMonth, error) {
+	rows, err := s.db.Query(`
+WITH sizes AS (
+	SELECT game_id, COUNT(*) AS size
+	FROM game_players
+	GROUP BY game_id
+)
+SELECT
+	strftime('%Y-%m', g.played_at) AS month,
+	COUNT(*),
+	AVG(sz.size),
+	MAX(sz.size),
+	MIN(sz.size)
+FROM games g
+JOIN sizes sz ON sz.game_id = g.id
+GROUP BY month
+ORDER BY month ASC
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []FieldSizeMonth
+	for rows.Next() {
+		var (
+			m      FieldSizeMonth
+			monthS string
+		)
+		if err := rows.Scan(&monthS, &m.Games, &m.AvgPlayers, &m.MaxPlayers, &m.MinPlayers); err != nil {
+			return nil, err
+		}
+		month, err := time.Parse(monthLayout, monthS)
+		if err != nil {
+			return nil, err
+		}
+		m.Month = month
+		months = append(months, m)
+	}
+	return months, rows.Err()
+}
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportGame is a game read from an import file, with players given by name.
+type ImportGame struct {
+	Line         int
+	PlayedAt     time.Time
+	Participants []string
+	Winner       string
+	Second       string
+}
+
+// ImportError is a line of an import file that can't be imported.
+type ImportError struct {
+	Line    int
+	Message string
+}
+
+// ImportPlan is the dry run of an import: the players that will be created,
+// the games that will be added and the games that are already recorded.
+type ImportPlan struct {
+	NewPlayers []string
+	Games      []ImportGame
+	Duplicates []ImportGame
+	Errors     []ImportError
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// PlanImport matches the names in games to players, ignoring case, and
+// checks each game without changing anything. Games identical to one already
+// recorded on the same date are reported as duplicates and skipped.
+func (s *Store) PlanImport(games []ImportGame) (ImportPlan, error) {
+	plan, _, err := planImport(s.db, games)
+	return plan, err
+}
+
+// Import creates the unknown players and adds the games of the plan in a
+// single transaction. Nothing is written if any game can't be imported.
+func (s *Store) Import(games []ImportGame, createdBy string) (ImportPlan, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return ImportPlan{}, err
+	}
+	defer tx.Rollback()
+
+	plan, ids, err := planImport(tx, games)
+	if err != nil {
+		return plan, err
+	}
+	if len(plan.Errors) > 0 {
+		return plan, fmt.Errorf("%d games can't be imported", len(plan.Errors))
+	}
+
+	for _, name := range plan.NewPlayers {
+		res, err := tx.Exec(`INSERT INTO players (name, emoji) VALUES (?, ?)`, name, emoji(name))
+		if err != nil {
+			return plan, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return plan, err
+		}
+		ids[strings.ToLower(name)] = int(id)
+	}
+
+	for _, g := range plan.Games {
+		participantIDs := make([]int, len(g.Participants))
+		for i, name := range g.Participants {
+			participantIDs[i] = ids[strings.ToLower(name)]
+		}
+		winnerID := ids[strings.ToLower(g.Winner)]
+		secondID := ids[strings.ToLower(g.Second)]
+		if _, err := addGame(tx, g.PlayedAt, participantIDs, winnerID, secondID, createdBy, 0); err != nil {
+			return plan, fmt.Errorf("line %d: %w", g.Line, err)
+		}
+	}
+
+	return plan, tx.Commit()
+}
+
+// planImport builds the plan and returns the lower-cased names of existing
+// players mapped to their IDs.
+func planImport(q querier, games []ImportGame) (ImportPlan, map[string]int, error) {
+	var plan ImportPlan
+
+	ids, err := playerIDsByName(q)
+	if err != nil {
+		return plan, nil, err
+	}
+	existing, err := gameKeyCounts(q)
+	if err != nil {
+		return plan, nil, err
+	}
+
+	newPlayers := make(map[string]bool)
+	for _, g := range games {
+		if msg := checkImportGame(g); msg != "" {
+			plan.Errors = append(plan.Errors, ImportError{Line: g.Line, Message: msg})
+			continue
+		}
+
+		known := true
+		for _, name := range g.Participants {
+			key := strings.ToLower(name)
+			if _, ok := ids[key]; ok {
+				continue
+			}
+			known = false
+			if !newPlayers[key] {
+				newPlayers[key] = true
+				plan.NewPlayers = append(plan.NewPlayers, name)
+			}
+		}
+
+		// Only games between existing players can already be recorded
+		if known {
+			key := importGameKey(g, ids)
+			if existing[key] > 0 {
+				existing[key]--
+				plan.Duplicates = append(plan.Duplicates, g)
+				continue
+			}
+		}
+		plan.Games = append(plan.Games, g)
+	}
+	return plan, ids, nil
+}
+
+// checkImportGame applies the same rules as validateGame to
+// names, returning a message if the game is invalid.
+func checkImportGame(g ImportGame) string {
+	seen := make(map[string]bool, len(g.Participants))
+	for _, name := range g.Participants {
+		seen[strings.ToLower(name)] = true
+	}
+	switch {
+	case len(seen) < 2:
+		return "needs at least two participants"
+	case g.Winner == "" || g.Second == "":
+		return "needs a winner and a 2nd place"
+	case strings.EqualFold(g.Winner, g.Second):
+		return "winner and 2nd place must be different players"
+	case !seen[strings.ToLower(g.Winner)]:
+		return "winner must be a participant"
+	case !seen[strings.ToLower(g.Second)]:
+		return "2nd place must be a participant"
+	}
+	return ""
+}
+
+func playerIDsByName(q querier) (map[string]int, error) {
+	rows, err := q.Query(`SELECT id, name FROM players`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int)
+	for rows.Next() {
+		var (
+			id   int
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		ids[strings.ToLower(name)] = id
+	}
+	return ids, rows.Err()
+}
+
+// gameKeyCounts counts the recorded games by date, placements and
+// participants.
+func gameKeyCounts(q querier) (map[string]int, error) {
+	rows, err := q.Query(`
+SELECT g.id, date(g.played_at), g.winner_id, g.second_id, gp.player_id
+FROM games g
+JOIN game_players gp ON gp.game_id = g.id
+ORDER BY g.id
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type game struct {
+		id, winner, second int
+		day                string
+		participants       []int
+	}
+	var (
+		counts  = make(map[string]int)
+		current *game
+	)
+	flush := func() {
+		if current != nil {
+			counts[gameKey(current.day, current.winner, current.second, current.participants)]++
+		}
+	}
+	for rows.Next() {
+		var (
+			g        game
+			playerID int
+		)
+		if err := rows.Scan(&g.id, &g.day, &g.winner, &g.second, &playerID); err != nil {
+			return nil, err
+		}
+		if current == nil || current.id != g.id {
+			flush()
+			current = &g
+		}
+		current.participants = append(current.participants, playerID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return counts, nil
+}
+
+func importGameKey(g ImportGame, ids map[string]int) string {
+	participants := make([]int, 0, len(g.Participants))
+	for _, name := range g.Participants {
+		participants = append(participants, ids[strings.ToLower(name)])
+	}
+	return gameKey(g.PlayedAt.Format(dayLayout), ids[strings.ToLower(g.Winner)], ids[strings.ToLower(g.Second)], Dedupe(participants))
+}
+
+func gameKey(day string, winnerID, secondID int, participantIDs []int) string {
+	sorted := append([]int(nil), participantIDs...)
+	sort.Ints(sorted)
+	parts := make([]string, len(sorted))
+	for i, id := range sorted {
+		parts[i] = strconv.Itoa(id)
+	}
+	return fmt.Sprintf("%s|%d|%d|%s", day, winnerID, secondID, strings.Join(parts, ","))
+}
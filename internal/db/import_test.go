@@ -0,0 +1,134 @@
+package db
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "hest.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestPlanImport(t *testing.T) {
+	store := newTestStore(t)
+	ids := make(map[string]int)
+	for _, name := range []string{"Anna", "Bo", "Carl"} {
+		id, err := store.AddPlayer(name)
+		if err != nil {
+			t.Fatalf("add player %s: %v", name, err)
+		}
+		ids[name] = id
+	}
+	recorded := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := store.AddGame(recorded, []int{ids["Anna"], ids["Bo"], ids["Carl"]}, ids["Anna"], ids["Bo"], "test", 0); err != nil {
+		t.Fatalf("add game: %v", err)
+	}
+
+	game := func(line int, day int, winner, second string, participants ...string) ImportGame {
+		return ImportGame{
+			Line:         line,
+			PlayedAt:     time.Date(2024, 5, day, 0, 0, 0, 0, time.UTC),
+			Participants: participants,
+			Winner:       winner,
+			Second:       second,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		games      []ImportGame
+		newPlayers []string
+		added      []int
+		duplicates []int
+		errors     []int
+	}{
+		{
+			name:       "recorded game in another case and order",
+			games:      []ImportGame{game(2, 1, "anna", "BO", "carl", "Bo", "ANNA")},
+			duplicates: []int{2},
+		},
+		{
+			name:       "recorded game twice in the file",
+			games:      []ImportGame{game(2, 1, "Anna", "Bo", "Anna", "Bo", "Carl"), game(3, 1, "Anna", "Bo", "Anna", "Bo", "Carl")},
+			added:      []int{3},
+			duplicates: []int{2},
+		},
+		{
+			name:  "same players on another date",
+			games: []ImportGame{game(2, 2, "Anna", "Bo", "Anna", "Bo", "Carl")},
+			added: []int{2},
+		},
+		{
+			name:  "different placements on the same date",
+			games: []ImportGame{game(2, 1, "Bo", "Anna", "Anna", "Bo", "Carl")},
+			added: []int{2},
+		},
+		{
+			name:  "subset of the players on the same date",
+			games: []ImportGame{game(2, 1, "Anna", "Bo", "Anna", "Bo")},
+			added: []int{2},
+		},
+		{
+			name:       "new player named in different cases",
+			games:      []ImportGame{game(2, 1, "Dora", "Anna", "Dora", "Anna"), game(3, 2, "DORA", "bo", "dora", "Bo")},
+			newPlayers: []string{"Dora"},
+			added:      []int{2, 3},
+		},
+		{
+			name:   "winner and second differ only in case",
+			games:  []ImportGame{game(2, 1, "Anna", "ANNA", "Anna", "Bo")},
+			errors: []int{2},
+		},
+		{
+			name:   "one player named twice",
+			games:  []ImportGame{game(2, 1, "Anna", "anna", "Anna", "anna")},
+			errors: []int{2},
+		},
+		{
+			name:   "winner not taking part",
+			games:  []ImportGame{game(2, 1, "Carl", "Bo", "Anna", "Bo")},
+			errors: []int{2},
+		},
+	}
+
+	lines := func(games []ImportGame) []int {
+		var result []int
+		for _, g := range games {
+			result = append(result, g.Line)
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := store.PlanImport(tt.games)
+			if err != nil {
+				t.Fatalf("plan import: %v", err)
+			}
+			if !reflect.DeepEqual(plan.NewPlayers, tt.newPlayers) {
+				t.Errorf("new players %v, want %v", plan.NewPlayers, tt.newPlayers)
+			}
+			if got := lines(plan.Games); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("added lines %v, want %v", got, tt.added)
+			}
+			if got := lines(plan.Duplicates); !reflect.DeepEqual(got, tt.duplicates) {
+				t.Errorf("duplicate lines %v, want %v", got, tt.duplicates)
+			}
+			var errLines []int
+			for _, e := range plan.Errors {
+				errLines = append(errLines, e.Line)
+			}
+			if !reflect.DeepEqual(errLines, tt.errors) {
+				t.Errorf("error lines %v, want %v: %v", errLines, tt.errors, plan.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package db
+
+import "time"
+
+// Reign is an uninterrupted period where a player was at the top of the
+// leaderboard. End is zero for the current leader.
+type Reign struct {
+	Player Player
+	Start  time.Time
+	End    time.Time
+	Days   int
+}
+
+// Current reports whether the reign is still going.
+func (r Reign) Current() bool {
+	return r.End.IsZero()
+}
+
+// LeadershipHistory replays the games oldest first and returns every change
+// of the #1 position, oldest first. The current reign is counted up to now.
+//
+// Only the players in a game can overtake the leader, so the replay keeps the
+// running totals and compares the participants against the current leader
+// instead of ranking everyone after every game.
+func (s *Store) LeadershipHistory(now time.Time) ([]Reign, error) {
+	games, err := s.ListGames(0)
+	if err != nil {
+		return nil, err
+	}
+
+	standings := make(map[int]*Player)
+	var leader *Player
+	var reigns []Reign
+	for i := len(games) - 1; i >= 0; i-- {
+		g := games[i]
+		for _, p := range g.Participants {
+			standing, ok := standings[p.ID]
+			if !ok {
+				standing = &Player{ID: p.ID, Name: p.Name, Emoji: p.Emoji}
+				standings[p.ID] = standing
+			}
+			standing.Games++
+			switch p.ID {
+			case g.Winner.ID:
+				standing.Wins++
+				standing.Points += 3
+			case g.Second.ID:
+				standing.Seconds++
+				standing.Points++
+			}
+		}
+		for _, p := range g.Participants {
+			if standing := standings[p.ID]; leader == nil || ranksAbove(*standing, *leader) {
+				leader = standing
+			}
+		}
+
+		if n := len(reigns); n > 0 && reigns[n-1].Player.ID == leader.ID {
+			continue
+		}
+		day := truncateDay(g.PlayedAt)
+
+		// Only the leader at the end of a day counts, so a lead that changed
+		// hands within the same day replaces the previous one
+		if n := len(reigns); n > 0 && reigns[n-1].Start.Equal(day) {
+			reigns = reigns[:n-1]
+			if n := len(reigns); n > 0 && reigns[n-1].Player.ID == leader.ID {
+				reigns[n-1].End = time.Time{}
+				continue
+			}
+		}
+		if n := len(reigns); n > 0 {
+			reigns[n-1].End = day
+		}
+		reigns = append(reigns, Reign{Player: Player{ID: leader.ID, Name: leader.Name, Emoji: leader.Emoji}, Start: day})
+	}
+
+	for i, r := range reigns {
+		end := r.End
+		if r.Current() {
+			end = truncateDay(now)
+		}
+		reigns[i].Days = int(end.Sub(r.Start).Hours() / 24)
+	}
+	return reigns, nil
+}
+
+// DaysAtTop sums the days each player has spent at #1, keyed by player ID.
+func DaysAtTop(reigns []Reign) map[int]int {
+	days := make(map[int]int)
+	for _, r := range reigns {
+		days[r.Player.ID] += r.Days
+	}
+	return days
+}
+
+// truncateDay returns midnight UTC of the date t falls on.
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,84 @@
+package db
+
+// NetworkEdge connects two players who have played together. Player1ID is
+// always the lower of the two IDs.
+type NetworkEdge struct {
+	Player1ID int
+	Player2ID int
+	Games     int
+}
+
+// TableMate is a player someone has shared games with, along with that
+// someone's record against them. Behind counts the shared games where the
+// mate finished ahead.
+type TableMate struct {
+	Mate   Player
+	Record H2HRecord
+	Behind int
+}
+
+// CoParticipation returns an edge for every pair of players who have taken
+// part in the same game, weighted by the number of shared games.
+func (s *Store) CoParticipation() ([]NetworkEdge, error) {
+	rows, err := s.db.Query(`
+SELECT a.player_id, b.player_id, COUNT(*) AS games
+FROM game_players a
+JOIN game_players b ON b.game_id = a.game_id AND b.player_id > a.player_id
+GROUP BY a.player_id, b.player_id
+ORDER BY games DESC
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var edges []NetworkEdge
+	for rows.Next() {
+		var e NetworkEdge
+		if err := rows.Scan(&e.Player1ID, &e.Player2ID, &e.Games); err != nil {
+			return nil, err
+		}
+		edges = append(edges, e)
+	}
+	return edges, rows.Err()
+}
+
+// TableMates returns the players most often in the same games as playerID,
+// up to limit, with the player's record against each of them.
+func (s *Store) TableMates(playerID, limit int) ([]TableMate, error) {
+	rows, err := s.db.Query(`
+SELECT o.id, o.name, o.emoji,
+	COUNT(*) AS games,
+	SUM(g.winner_id = me.player_id) AS wins,
+	SUM(g.second_id = me.player_id) AS seconds,
+	SUM(g.winner_id = me.player_id
+		OR (g.second_id = me.player_id AND g.winner_id != o.id)) AS ahead,
+	SUM(g.winner_id = o.id
+		OR (g.second_id = o.id AND g.winner_id != me.player_id)) AS behind
+FROM game_players me
+JOIN game_players gp ON gp.game_id = me.game_id AND gp.player_id != me.player_id
+JOIN players o ON o.id = gp.player_id
+JOIN games g ON g.id = me.game_id
+WHERE me.player_id = ?
+GROUP BY o.id
+ORDER BY games DESC, o.name ASC
+LIMIT ?
+`, playerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mates []TableMate
+	for rows.Next() {
+		var m TableMate
+		if err := rows.Scan(&m.Mate.ID, &m.Mate.Name, &m.Mate.Emoji,
+			&m.Record.Games, &m.Record.Wins, &m.Record.Seconds, &m.Record.Ahead, &m.Behind); err != nil {
+			return nil, err
+		}
+		m.Record.Points = m.Record.Wins*3 + m.Record.Seconds
+		m.Record.PPG = float64(m.Record.Points) / float64(m.Record.Games)
+		mates = append(mates, m)
+	}
+	return mates, rows.Err()
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// projectionWindow is the number of most recent games used to estimate how
+// often the group plays, who shows up and how well they do.
+const projectionWindow = 50
+
+// minStrength keeps players without recent points in the draw.
+const minStrength = 0.1
+
+// PlayerProjection is a player's outlook for the rest of the season.
+type PlayerProjection struct {
+	Player         Player
+	RecentPPG      float64
+	Attendance     float64
+	ExpectedPoints float64
+	WinChance      float64
+	// PointsToClinch is the number of points the player needs to be certain
+	// to finish first. It is 0 when already clinched and -1 when the player
+	// can't clinch on their own.
+	PointsToClinch int
+}
+
+// SeasonProjection is the result of simulating the rest of the season.
+type SeasonProjection struct {
+	Season         DateRange
+	RemainingGames int
+	Runs           int
+	Players        []PlayerProjection
+}
+
+type projectionInput struct {
+	player   Player
+	ppg      float64
+	attended float64
+}
+
+// ProjectSeason simulates the games left of season runs times and returns
+// each player's chance of finishing first. The season must have an end, and
+// only games within the season count towards the standings and form. The
+// number of remaining games is extrapolated from the pace of the last
+// projectionWindow games of the season, and in each simulated game a player
+// takes part with their recent attendance rate and places with a weight given
+// by their recent points per game.
+func (s *Store) ProjectSeason(season DateRange, now time.Time, runs int, rng *rand.Rand) (SeasonProjection, error) {
+	projection := SeasonProjection{Season: season, Runs: runs}
+
+	var players []Player
+	err := s.EachStanding(season, func(_ int, p Player) error {
+		players = append(players, p)
+		return nil
+	})
+	if err != nil {
+		return projection, err
+	}
+
+	where, args := season.where("played_at")
+	args = append(args, projectionWindow)
+
+	var (
+		windowGames int
+		windowStart string
+	)
+	err = s.db.QueryRow(`
+WITH recent AS (
+	SELECT played_at FROM games
+	WHERE `+where+`
+	ORDER BY played_at DESC, id DESC LIMIT ?
+)
+SELECT COUNT(*), COALESCE(MIN(date(played_at)), '') FROM recent
+`, args...).Scan(&windowGames, &windowStart)
+	if err != nil {
+		return projection, err
+	}
+
+	rows, err := s.db.Query(`
+WITH recent AS (
+	SELECT id, winner_id, second_id FROM games
+	WHERE `+where+`
+	ORDER BY played_at DESC, id DESC LIMIT ?
+)
+SELECT gp.player_id,
+	COUNT(*),
+	SUM(r.winner_id = gp.player_id) * 3 + SUM(r.second_id = gp.player_id)
+FROM recent r
+JOIN game_players gp ON gp.game_id = r.id
+GROUP BY gp.player_id
+`, args...)
+	if err != nil {
+		return projection, err
+	}
+	defer rows.Close()
+
+	type recentRecord struct{ games, points int }
+	recent := make(map[int]recentRecord)
+	for rows.Next() {
+		var (
+			playerID int
+			r        recentRecord
+		)
+		if err := rows.Scan(&playerID, &r.games, &r.points); err != nil {
+			return projection, err
+		}
+		recent[playerID] = r
+	}
+	if err := rows.Err(); err != nil {
+		return projection, err
+	}
+
+	// Extrapolate the pace of recent games to the days left, counting the
+	// whole last day of the season
+	end := season.To.AddDate(0, 0, 1)
+	if windowGames > 0 && end.After(now) {
+		start, err := time.Parse(dayLayout, windowStart)
+		if err != nil {
+			return projection, err
+		}
+		days := math.Max(now.Sub(start).Hours()/24, 1)
+		daysLeft := end.Sub(now).Hours() / 24
+		projection.RemainingGames = int(math.Round(float64(windowGames) / days * daysLeft))
+	}
+
+	inputs := make([]projectionInput, len(players))
+	for i, p := range players {
+		in := projectionInput{player: p}
+		if r, ok := recent[p.ID]; ok {
+			in.ppg = float64(r.points) / float64(r.games)
+			in.attended = float64(r.games) / float64(windowGames)
+		}
+		inputs[i] = in
+	}
+
+	firsts, points := simulateSeason(inputs, projection.RemainingGames, runs, rng)
+
+	leaderMax := make([]int, len(players))
+	for i := range players {
+		for j, o := range players {
+			if j != i {
+				leaderMax[i] = max(leaderMax[i], o.Points+3*projection.RemainingGames)
+			}
+		}
+	}
+
+	for i, in := range inputs {
+		pp := PlayerProjection{
+			Player:     in.player,
+			RecentPPG:  in.ppg,
+			Attendance: in.attended,
+		}
+		if runs > 0 {
+			pp.WinChance = float64(firsts[i]) / float64(runs)
+			pp.ExpectedPoints = float64(points[i]) / float64(runs)
+		}
+
+		// A tie on points could go either way on the tiebreakers, so a clinch
+		// needs one point more than the best anyone else can reach
+		needed := leaderMax[i] - in.player.Points + 1
+		switch {
+		case len(players) == 1 || needed <= 0:
+			pp.PointsToClinch = 0
+		case needed > 3*projection.RemainingGames:
+			pp.PointsToClinch = -1
+		default:
+			pp.PointsToClinch = needed
+		}
+		projection.Players = append(projection.Players, pp)
+	}
+
+	sort.SliceStable(projection.Players, func(i, j int) bool {
+		return projection.Players[i].WinChance > projection.Players[j].WinChance
+	})
+	return projection, nil
+}
+
+// simulateSeason plays the remaining games runs times and returns, per input,
+// how many runs they finished first in and their total points across runs.
+func simulateSeason(inputs []projectionInput, remaining, runs int, rng *rand.Rand) (firsts, points []int) {
+	firsts = make([]int, len(inputs))
+	points = make([]int, len(inputs))
+	if len(inputs) == 0 {
+		return firsts, points
+	}
+
+	standings := make([]Player, len(inputs))
+	field := make([]int, 0, len(inputs))
+	for run := 0; run < runs; run++ {
+		for i, in := range inputs {
+			standings[i] = in.player
+		}
+
+		for g := 0; g < remaining; g++ {
+			field = field[:0]
+			for i, in := range inputs {
+				if rng.Float64() < in.attended {
+					field = append(field, i)
+				}
+			}
+			if len(field) < 2 {
+				continue
+			}
+
+			winner := drawWeighted(inputs, field, rng)
+			field = removeIndex(field, winner)
+			second := drawWeighted(inputs, field, rng)
+
+			for _, i := range append(field, winner) {
+				standings[i].Games++
+			}
+			standings[winner].Wins++
+			standings[winner].Points += 3
+			standings[second].Seconds++
+			standings[second].Points++
+		}
+
+		first := 0
+		for i := range standings {
+			points[i] += standings[i].Points
+			if ranksAbove(standings[i], standings[first]) {
+				first = i
+			}
+		}
+		firsts[first]++
+	}
+	return firsts, points
+}
+
+// drawWeighted picks an input index from field weighted by recent PPG.
+func drawWeighted(inputs []projectionInput, field []int, rng *rand.Rand) int {
+	var total float64
+	for _, i := range field {
+		total += inputs[i].ppg + minStrength
+	}
+	pick := rng.Float64() * total
+	for _, i := range field {
+		pick -= inputs[i].ppg + minStrength
+		if pick < 0 {
+			return i
+		}
+	}
+	return field[len(field)-1]
+}
+
+func removeIndex(field []int, value int) []int {
+	for k, i := range field {
+		if i == value {
+			return append(field[:k], field[k+1:]...)
+		}
+	}
+	return field
+}
@@ -0,0 +1,175 @@
+package db
+
+import "time"
+
+// Opponent is another player with a count, such as shared games or the
+// number of times they finished ahead.
+type Opponent struct {
+	Player Player
+	Count  int
+}
+
+// MonthResult is a player's points in a single month.
+type MonthResult struct {
+	Month  time.Time
+	Games  int
+	Wins   int
+	Points int
+}
+
+// YearReview summarises a player's year. Rank fields are 0 when the player
+// had no rank at that point, and pointer fields are nil when there is
+// nothing to show.
+type YearReview struct {
+	Player       Player
+	Year         int
+	Games        int
+	Wins         int
+	Seconds      int
+	Points       int
+	PPG          float64
+	BestMonth    *MonthResult
+	MostCommon   *Opponent
+	Nemesis      *Opponent
+	WinStreak    int
+	PodiumStreak int
+	BiggestWin   *Game
+	StartRank    int
+	EndRank      int
+}
+
+// PlayerYearReview builds a player's review of the given year from their games
+// and rank history.
+func (s *Store) PlayerYearReview(playerID, year int) (YearReview, error) {
+	review := YearReview{Year: year}
+
+	players, err := s.PlayersByIDs([]int{playerID})
+	if err != nil {
+		return review, err
+	}
+	if len(players) == 0 {
+		return review, ErrNotFound
+	}
+	review.Player = players[0]
+
+	games, err := s.PlayerGames(playerID, 0)
+	if err != nil {
+		return review, err
+	}
+
+	// PlayerGames is newest first, streaks are counted oldest first
+	var yearGames []Game
+	for i := len(games) - 1; i >= 0; i-- {
+		if games[i].PlayedAt.Year() == year {
+			yearGames = append(yearGames, games[i])
+		}
+	}
+
+	var (
+		months       = make(map[time.Month]*MonthResult)
+		shared       = make(map[int]*Opponent)
+		ahead        = make(map[int]*Opponent)
+		win, podium  int
+		biggestField int
+	)
+	for _, g := range yearGames {
+		review.Games++
+		points := 0
+		switch playerID {
+		case g.Winner.ID:
+			review.Wins++
+			points = 3
+			win++
+			podium++
+		case g.Second.ID:
+			review.Seconds++
+			points = 1
+			win = 0
+			podium++
+		default:
+			win = 0
+			podium = 0
+		}
+		review.WinStreak = max(review.WinStreak, win)
+		review.PodiumStreak = max(review.PodiumStreak, podium)
+
+		if g.Winner.ID == playerID && len(g.Participants) >= biggestField {
+			biggestField = len(g.Participants)
+			biggest := g
+			review.BiggestWin = &biggest
+		}
+
+		m := months[g.PlayedAt.Month()]
+		if m == nil {
+			m = &MonthResult{Month: time.Date(year, g.PlayedAt.Month(), 1, 0, 0, 0, 0, time.UTC)}
+			months[g.PlayedAt.Month()] = m
+		}
+		m.Games++
+		m.Points += points
+		if points == 3 {
+			m.Wins++
+		}
+
+		for _, o := range g.Participants {
+			if o.ID == playerID {
+				continue
+			}
+			if shared[o.ID] == nil {
+				shared[o.ID] = &Opponent{Player: o}
+			}
+			shared[o.ID].Count++
+			if finishedAhead(o.ID, playerID, g.Winner.ID, g.Second.ID) {
+				if ahead[o.ID] == nil {
+					ahead[o.ID] = &Opponent{Player: o}
+				}
+				ahead[o.ID].Count++
+			}
+		}
+	}
+
+	review.Points = review.Wins*3 + review.Seconds
+	if review.Games > 0 {
+		review.PPG = float64(review.Points) / float64(review.Games)
+	}
+
+	for _, m := range months {
+		best := review.BestMonth
+		if best == nil || m.Points > best.Points ||
+			(m.Points == best.Points && m.Month.Before(best.Month)) {
+			review.BestMonth = m
+		}
+	}
+	review.MostCommon = topOpponent(shared)
+	review.Nemesis = topOpponent(ahead)
+
+	history, err := s.PlayerRankHistory(playerID)
+	if err != nil {
+		return review, err
+	}
+	for _, h := range history {
+		switch {
+		case h.PlayedAt.Year() < year:
+			review.StartRank = h.Rank
+		case h.PlayedAt.Year() == year:
+			review.EndRank = h.Rank
+		}
+	}
+	if review.EndRank == 0 {
+		review.EndRank = review.StartRank
+	}
+
+	return review, nil
+}
+
+// topOpponent returns the opponent with the highest count, breaking ties by
+// name, or nil if there are none.
+func topOpponent(opponents map[int]*Opponent) *Opponent {
+	var top *Opponent
+	for _, o := range opponents {
+		if top == nil || o.Count > top.Count ||
+			(o.Count == top.Count && o.Player.Name < top.Player.Name) {
+			top = o
+		}
+	}
+	return top
+}
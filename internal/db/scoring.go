@@ -0,0 +1,33 @@
+package db
+
+import "sort"
+
+// ScoringScheme is the number of points given for winning, for second place
+// and for taking part without reaching the podium.
+type ScoringScheme struct {
+	Win    int
+	Second int
+	Played int
+}
+
+// DefaultScoring is the scoring used by the leaderboard.
+var DefaultScoring = ScoringScheme{Win: 3, Second: 1}
+
+// Rescore returns the players with points and PPG recomputed under scheme,
+// ordered with the same tiebreakers as ListPlayersByPoints.
+func Rescore(players []Player, scheme ScoringScheme) []Player {
+	rescored := make([]Player, len(players))
+	for i, p := range players {
+		others := p.Games - p.Wins - p.Seconds
+		p.Points = p.Wins*scheme.Win + p.Seconds*scheme.Second + others*scheme.Played
+		p.PPG = 0
+		if p.Games > 0 {
+			p.PPG = float64(p.Points) / float64(p.Games)
+		}
+		rescored[i] = p
+	}
+	sort.SliceStable(rescored, func(i, j int) bool {
+		return ranksAbove(rescored[i], rescored[j])
+	})
+	return rescored
+}
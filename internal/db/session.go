@@ -0,0 +1,162 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Session is a game night: the games played on the same date, or the games
+// explicitly given the same session id.
+type Session struct {
+	// ID is the explicit session id, or 0 when grouped by date.
+	ID        int
+	Date      time.Time
+	Games     []Game
+	Standings []Player
+}
+
+// Winner returns the player on top of the session's standings.
+func (s Session) Winner() Player {
+	if len(s.Standings) == 0 {
+		return Player{}
+	}
+	return s.Standings[0]
+}
+
+// Anchor returns the fragment identifying the session on the sessions page:
+// its date, followed by the id of an explicit session.
+func (s Session) Anchor() string {
+	anchor := s.Date.Format(dayLayout)
+	if s.ID != 0 {
+		anchor += fmt.Sprintf("-%d", s.ID)
+	}
+	return anchor
+}
+
+// SessionAnchors returns the anchor of the session each game belongs to on
+// the sessions page, keyed by game ID. An explicit session is dated by its
+// earliest game, which need not be among the given games.
+func (s *Store) SessionAnchors(games []Game) (map[int]string, error) {
+	var ids []int
+	for _, g := range games {
+		if g.SessionID != 0 {
+			ids = append(ids, g.SessionID)
+		}
+	}
+
+	starts := make(map[int]time.Time)
+	if ids = Dedupe(ids); len(ids) > 0 {
+		placeholders, args := buildPlaceholders(ids)
+		rows, err := s.db.Query(`
+SELECT session_id, date(MIN(played_at))
+FROM games
+WHERE session_id IN (`+placeholders+`)
+GROUP BY session_id
+`, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				id    int
+				start string
+			)
+			if err := rows.Scan(&id, &start); err != nil {
+				return nil, err
+			}
+			date, err := time.Parse(dayLayout, start)
+			if err != nil {
+				return nil, err
+			}
+			starts[id] = date
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	anchors := make(map[int]string, len(games))
+	for _, g := range games {
+		session := Session{ID: g.SessionID, Date: g.PlayedAt}
+		if start, ok := starts[g.SessionID]; ok {
+			session.Date = start
+		}
+		anchors[g.ID] = session.Anchor()
+	}
+	return anchors, nil
+}
+
+// sessionKey returns the key games are grouped by.
+func sessionKey(g Game) string {
+	if g.SessionID != 0 {
+		return fmt.Sprintf("id:%d", g.SessionID)
+	}
+	return "date:" + g.PlayedAt.Format(dayLayout)
+}
+
+// GroupSessions groups games into sessions. Sessions and the games within them
+// keep the order of the given games.
+func GroupSessions(games []Game) []Session {
+	var sessions []Session
+	index := make(map[string]int)
+	for _, g := range games {
+		key := sessionKey(g)
+		i, ok := index[key]
+		if !ok {
+			i = len(sessions)
+			index[key] = i
+			sessions = append(sessions, Session{ID: g.SessionID, Date: g.PlayedAt})
+		}
+		sessions[i].Games = append(sessions[i].Games, g)
+		if g.PlayedAt.Before(sessions[i].Date) {
+			sessions[i].Date = g.PlayedAt
+		}
+	}
+
+	for i := range sessions {
+		sessions[i].Standings = sessionStandings(sessions[i].Games)
+	}
+	return sessions
+}
+
+// sessionStandings computes a leaderboard from the given games only, using the
+// same scoring and tiebreakers as ListPlayersByPoints.
+func sessionStandings(games []Game) []Player {
+	byID := make(map[int]*Player)
+	for _, g := range games {
+		for _, p := range g.Participants {
+			if _, ok := byID[p.ID]; !ok {
+				byID[p.ID] = &Player{ID: p.ID, Name: p.Name, Emoji: p.Emoji}
+			}
+			standing := byID[p.ID]
+			standing.Games++
+			if p.ID == g.Winner.ID {
+				standing.Wins++
+			} else if p.ID == g.Second.ID {
+				standing.Seconds++
+			}
+		}
+	}
+
+	standings := make([]Player, 0, len(byID))
+	for _, p := range byID {
+		p.Points = p.Wins*3 + p.Seconds
+		p.PPG = float64(p.Points) / float64(p.Games)
+		standings = append(standings, *p)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		return ranksAbove(standings[i], standings[j])
+	})
+	return standings
+}
+
+// ListSessions returns all sessions, newest first.
+func (s *Store) ListSessions() ([]Session, error) {
+	games, err := s.ListGames(0)
+	if err != nil {
+		return nil, err
+	}
+	return GroupSessions(games), nil
+}
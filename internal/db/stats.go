@@ -0,0 +1,160 @@
+package db
+
+import "time"
+
+// GroupTotals are overall numbers for the whole group.
+type GroupTotals struct {
+	Games           int
+	Players         int
+	ActivePlayers   int
+	AvgFieldSize    float64
+	DistinctWinners int
+	FirstGame       time.Time
+	LastGame        time.Time
+}
+
+// MonthStats summarises the games played in a month.
+type MonthStats struct {
+	Month           time.Time
+	Games           int
+	DistinctWinners int
+}
+
+// WinShare is a player's share of all wins.
+type WinShare struct {
+	Player Player
+	Wins   int
+	Share  float64
+}
+
+// GroupTotals returns overall numbers for the group. FirstGame and LastGame
+// are zero when no games have been played.
+func (s *Store) GroupTotals() (GroupTotals, error) {
+	var (
+		totals      GroupTotals
+		first, last string
+	)
+	err := s.db.QueryRow(`
+WITH sizes AS (
+	SELECT game_id, COUNT(*) AS size
+	FROM game_players
+	GROUP BY game_id
+)
+SELECT
+	(SELECT COUNT(*) FROM games),
+	(SELECT COUNT(*) FROM players),
+	(SELECT COUNT(DISTINCT player_id) FROM game_players),
+	COALESCE((SELECT AVG(size) FROM sizes), 0),
+	(SELECT COUNT(DISTINCT winner_id) FROM games),
+	COALESCE((SELECT MIN(date(played_at)) FROM games), ''),
+	COALESCE((SELECT MAX(date(played_at)) FROM games), '')
+`).Scan(&totals.Games, &totals.Players, &totals.ActivePlayers, &totals.AvgFieldSize,
+		&totals.DistinctWinners, &first, &last)
+	if err != nil {
+		return totals, err
+	}
+
+	if first != "" {
+		if totals.FirstGame, err = time.Parse(dayLayout, first); err != nil {
+			return totals, err
+		}
+		if totals.LastGame, err = time.Parse(dayLayout, last); err != nil {
+			return totals, err
+		}
+	}
+	return totals, nil
+}
+
+// MonthlyStats returns the number of games and distinct winners for each
+// month with games, oldest first.
+func (s *Store) MonthlyStats() ([]MonthStats, error) {
+	rows, err := s.db.Query(`
+SELECT
+	strftime('%Y-%m', played_at) AS month,
+	COUNT(*),
+	COUNT(DISTINCT winner_id)
+FROM games
+GROUP BY month
+ORDER BY month ASC
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []MonthStats
+	for rows.Next() {
+		var (
+			m      MonthStats
+			monthS string
+		)
+		if err := rows.Scan(&monthS, &m.Games, &m.DistinctWinners); err != nil {
+			return nil, err
+		}
+		month, err := time.Parse(monthLayout, monthS)
+		if err != nil {
+			return nil, err
+		}
+		m.Month = month
+		months = append(months, m)
+	}
+	return months, rows.Err()
+}
+
+// WinDistribution returns every winner's share of all wins, most wins first.
+func (s *Store) WinDistribution() ([]WinShare, error) {
+	rows, err := s.db.Query(`
+SELECT p.id, p.name, p.emoji, COUNT(*) AS wins,
+	CAST(COUNT(*) AS REAL) / (SELECT COUNT(*) FROM games) AS share
+FROM games g
+JOIN players p ON p.id = g.winner_id
+GROUP BY p.id
+ORDER BY wins DESC, p.name ASC
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []WinShare
+	for rows.Next() {
+		var ws WinShare
+		if err := rows.Scan(&ws.Player.ID, &ws.Player.Name, &ws.Player.Emoji, &ws.Wins, &ws.Share); err != nil {
+			return nil, err
+		}
+		shares = append(shares, ws)
+	}
+	return shares, rows.Err()
+}
+
+// BusiestDays returns the days with the most games, up to limit.
+func (s *Store) BusiestDays(limit int) ([]ActivityDay, error) {
+	rows, err := s.db.Query(`
+SELECT date(played_at) AS day, COUNT(*) AS games
+FROM games
+GROUP BY day
+ORDER BY games DESC, day DESC
+LIMIT ?
+`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanActivityDays(rows)
+}
+
+// EffectiveWinners is the inverse Herfindahl index of the win shares: the
+// number of equally strong winners that would give the same concentration.
+// It equals the number of winners when wins are spread evenly and approaches
+// 1 when a single player wins everything.
+func EffectiveWinners(shares []WinShare) float64 {
+	var sum float64
+	for _, ws := range shares {
+		sum += ws.Share * ws.Share
+	}
+	if sum == 0 {
+		return 0
+	}
+	return 1 / sum
+}
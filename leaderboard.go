@@ -3,11 +3,32 @@ package main
 import (
 	"net/http"
 	"sort"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
 )
 
+// formLength is the number of recent games shown in the form guide.
+const formLength = 5
+
 type PlayerWithRank struct {
 	Player
 	OriginalRank int
+	Form         []db.FormResult
+}
+
+// leaderColors are used to tell the #1 holders apart on the timeline.
+var leaderColors = []string{"#464646", "#e4572e", "#29335c", "#f3a712", "#669bbc", "#a8c686", "#8f2d56", "#5c4d7d"}
+
+type leaderReign struct {
+	db.Reign
+	Color string
+}
+
+type leaderTotal struct {
+	Player db.Player
+	Days   int
+	Color  string
 }
 
 type leaderboardForm struct {
@@ -16,6 +37,10 @@ type leaderboardForm struct {
 	Players []PlayerWithRank
 	SortBy  string
 	SortDir string
+	Reigns  []leaderReign
+	Leaders []leaderTotal
+
+	Projection *db.SeasonProjection
 }
 
 func newLeaderboardForm() *leaderboardForm {
@@ -39,6 +64,40 @@ func (l leaderboardForm) withPlayers(leaderboard []Player) leaderboardForm {
 	return l
 }
 
+// withForm adds each player's recent results, most recent first.
+func (l leaderboardForm) withForm(form map[int][]db.FormResult) leaderboardForm {
+	for i, p := range l.Players {
+		l.Players[i].Form = form[p.ID]
+	}
+	return l
+}
+
+// withLeadership adds the timeline of #1 holders and each holder's total days
+// at the top, most days first.
+func (l leaderboardForm) withLeadership(reigns []db.Reign) leaderboardForm {
+	days := db.DaysAtTop(reigns)
+	colors := make(map[int]string)
+	for _, r := range reigns {
+		if _, ok := colors[r.Player.ID]; !ok {
+			colors[r.Player.ID] = leaderColors[len(colors)%len(leaderColors)]
+			l.Leaders = append(l.Leaders, leaderTotal{Player: r.Player, Days: days[r.Player.ID]})
+		}
+		l.Reigns = append(l.Reigns, leaderReign{Reign: r, Color: colors[r.Player.ID]})
+	}
+	for i, leader := range l.Leaders {
+		l.Leaders[i].Color = colors[leader.Player.ID]
+	}
+	sort.SliceStable(l.Leaders, func(i, j int) bool {
+		return l.Leaders[i].Days > l.Leaders[j].Days
+	})
+	return l
+}
+
+func (l leaderboardForm) withProjection(projection db.SeasonProjection) leaderboardForm {
+	l.Projection = &projection
+	return l
+}
+
 func (l leaderboardForm) withSort(sortBy, sortDir string) leaderboardForm {
 	l.SortBy = sortBy
 	l.SortDir = sortDir
@@ -90,14 +149,41 @@ func (a *App) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	form := newLeaderboardForm().withPlayers(players).withSort(sortBy, sortDir)
+	recentForm, err := a.store.RecentForm(formLength)
+	if err != nil {
+		http.Error(w, "loading form guide", http.StatusInternalServerError)
+		return
+	}
+
+	form := newLeaderboardForm().
+		withPlayers(players).
+		withForm(recentForm).
+		withSort(sortBy, sortDir)
 
 	// If HTMX request, return only the table partial
 	if r.Header.Get("HX-Request") == "true" {
-		renderTemplate(w, "content", form, "templates/leaderboard.html")
+		renderTemplate(w, "leaderboard", form, "templates/leaderboard.html")
+		return
+	}
+
+	reigns, err := a.store.LeadershipHistory(time.Now())
+	if err != nil {
+		http.Error(w, "loading leadership history", http.StatusInternalServerError)
 		return
 	}
+	form = form.withLeadership(reigns)
+
+	// The season runs to the end of its last day
+	if current, ok := season(); ok && current.To.AddDate(0, 0, 1).After(time.Now()) {
+		now := time.Now()
+		projection, err := a.store.ProjectSeason(current, now, projectionRuns, projectionRand(now))
+		if err != nil {
+			http.Error(w, "loading season projection", http.StatusInternalServerError)
+			return
+		}
+		form = form.withProjection(projection)
+	}
 
 	// Otherwise, render the full page
-	renderTemplate(w, "layout", form, "templates/layout.html", "templates/leaderboard.html")
+	renderTemplate(w, "layout", form, "templates/layout.html", "templates/leaderboard.html", "templates/export.html")
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"net/http"
@@ -17,8 +18,20 @@ func main() {
 	}
 	defer store.Close()
 
+	if len(os.Args) > 1 {
+		if err := runCommand(store, os.Args[1], os.Args[2:]); err != nil {
+			store.Close()
+			log.Fatal(err)
+		}
+		return
+	}
+
 	app := newApp(store)
 
+	if cfg, ok := backupSettings(); ok {
+		go scheduleBackups(context.Background(), store, cfg)
+	}
+
 	port := "8080"
 	if portEnv := os.Getenv("HEST_PORT"); portEnv != "" {
 		port = portEnv
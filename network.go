@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+type networkView struct {
+	Path    string
+	Title   string
+	Players []Player
+	Edges   []db.NetworkEdge
+}
+
+func newNetworkView(players []Player, edges []db.NetworkEdge) networkView {
+	return networkView{
+		Path:    "/network",
+		Title:   "Netværk",
+		Players: players,
+		Edges:   edges,
+	}
+}
+
+func (a *App) handleNetwork(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	players, err := a.ListPlayers()
+	if err != nil {
+		http.Error(w, "failed to load players", http.StatusInternalServerError)
+		return
+	}
+
+	edges, err := a.store.CoParticipation()
+	if err != nil {
+		http.Error(w, "failed to load network", http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, "layout", newNetworkView(players, edges), "templates/layout.html", "templates/network.html")
+}
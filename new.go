@@ -14,6 +14,7 @@ type gameForm struct {
 	Title    string
 	Players  []Player
 	PlayedAt string
+	Session  string
 	Error    string
 	Success  string
 	WinnerID int
@@ -55,6 +56,11 @@ func (f gameForm) withDate(playedAt string) gameForm {
 	return f
 }
 
+func (f gameForm) withSession(session string) gameForm {
+	f.Session = strings.TrimSpace(session)
+	return f
+}
+
 func (a *App) handleAddPlayer(w http.ResponseWriter, r *http.Request) {
 	_, ok := ensureAuthAndForm(w, r)
 	if !ok {
@@ -67,7 +73,7 @@ func (a *App) handleAddPlayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.store.AddPlayer(name); err != nil {
+	if _, err := a.store.AddPlayer(name); err != nil {
 		slog.Error("could not add player", "error", err)
 		http.Error(w, "could not add player", http.StatusBadRequest)
 		return
@@ -183,7 +189,9 @@ func (a *App) saveGameCommon(w http.ResponseWriter, r *http.Request) ([]Player,
 		return nil, false
 	}
 
-	form := newGameForm(players).withDate(r.FormValue("played_at"))
+	form := newGameForm(players).
+		withDate(r.FormValue("played_at")).
+		withSession(r.FormValue("session_id"))
 
 	winnerID, err := parsePlayer(r.FormValue("winner_id"))
 	if err != nil {
@@ -197,7 +205,7 @@ func (a *App) saveGameCommon(w http.ResponseWriter, r *http.Request) ([]Player,
 	}
 
 	form = form.withSelection(winnerID, secondID)
-	if msg := validatePlacement(winnerID, secondID, uniqueIDs); msg != "" {
+	if _, msg := validatePlacement(winnerID, secondID, uniqueIDs); msg != "" {
 		a.renderScoring(w, r, form.withError(msg))
 		return nil, false
 	}
@@ -208,7 +216,13 @@ func (a *App) saveGameCommon(w http.ResponseWriter, r *http.Request) ([]Player,
 		return nil, false
 	}
 
-	if err := a.store.AddGame(playedAt, uniqueIDs, winnerID, secondID, username); err != nil {
+	sessionID, msg := parseSession(form.Session)
+	if msg != "" {
+		a.renderScoring(w, r, form.withError(msg))
+		return nil, false
+	}
+
+	if _, err := a.store.AddGame(playedAt, uniqueIDs, winnerID, secondID, username, sessionID); err != nil {
 		http.Error(w, "db error", http.StatusInternalServerError)
 		return nil, false
 	}
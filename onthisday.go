@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+type onThisDayView struct {
+	Date  time.Time
+	Games []Game
+}
+
+// handleOnThisDay renders the partial listing games played on today's date in
+// earlier years. The leaderboard loads it with HTMX.
+func (a *App) handleOnThisDay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	date := time.Now()
+	if raw := strings.TrimSpace(r.URL.Query().Get("date")); raw != "" {
+		var err error
+		date, err = time.Parse(dateLayout, raw)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	gamesDB, err := a.store.GamesOnThisDay(date)
+	if err != nil {
+		http.Error(w, "failed to load games", http.StatusInternalServerError)
+		return
+	}
+
+	games := make([]Game, len(gamesDB))
+	for i, g := range gamesDB {
+		games[i] = Game(g)
+	}
+
+	renderTemplate(w, "onthisday", onThisDayView{Date: date, Games: games}, "templates/onthisday.html")
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlmjohnson/versioninfo"
+)
+
+// apiRoute is a pattern registered on the mux together with the operations it
+// serves. The OpenAPI document is generated from the same routes and the
+// request and response types, so the docs can't drift from the handlers.
+type apiRoute struct {
+	Pattern    string
+	Handler    http.HandlerFunc
+	Operations []apiOperation
+}
+
+type apiOperation struct {
+	Method  string
+	Summary string
+	Params  []apiParam
+	// Request and Response are zero values of the body types, or nil when
+	// the operation has no body.
+	Request  any
+	Response any
+	Status   int
+	Errors   []int
+	Auth     bool
+}
+
+type apiParam struct {
+	Name        string
+	In          string
+	Description string
+}
+
+func (a *App) apiRoutes() []apiRoute {
+	playerID := apiParam{Name: "id", In: "path", Description: "Player id"}
+	return []apiRoute{
+		{
+			Pattern: "/api/v1/leaderboard",
+			Handler: a.handleAPILeaderboard,
+			Operations: []apiOperation{{
+				Method:   http.MethodGet,
+				Summary:  "Players ranked by points with the leaderboard tiebreakers",
+				Response: []apiLeaderboardEntry{},
+				Status:   http.StatusOK,
+			}},
+		},
+		{
+			Pattern: "/api/v1/players",
+			Handler: a.handleAPIPlayers,
+			Operations: []apiOperation{{
+				Method:   http.MethodGet,
+				Summary:  "All players sorted by name",
+				Response: []apiPlayer{},
+				Status:   http.StatusOK,
+			}, {
+				Method:   http.MethodPost,
+				Summary:  "Create a player",
+				Request:  apiPlayerRequest{},
+				Response: apiPlayer{},
+				Status:   http.StatusCreated,
+				Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict, http.StatusUnprocessableEntity},
+				Auth:     true,
+			}},
+		},
+		{
+			Pattern: "/api/v1/players/{id}",
+			Handler: a.handleAPIPlayer,
+			Operations: []apiOperation{{
+				Method:   http.MethodGet,
+				Summary:  "A player with their game and rank history",
+				Params:   []apiParam{playerID},
+				Response: apiPlayerDetail{},
+				Status:   http.StatusOK,
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}},
+		},
+		{
+			Pattern: "/api/v1/games",
+			Handler: a.handleAPIGames,
+			Operations: []apiOperation{{
+				Method:   http.MethodGet,
+				Summary:  "All games, newest first",
+				Response: []apiGame{},
+				Status:   http.StatusOK,
+			}, {
+				Method:   http.MethodPost,
+				Summary:  "Record a game",
+				Request:  apiGameRequest{},
+				Response: apiGame{},
+				Status:   http.StatusCreated,
+				Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusUnprocessableEntity},
+				Auth:     true,
+			}},
+		},
+		{
+			Pattern: "/api/v1/games/{id}",
+			Handler: a.handleAPIGame,
+			Operations: []apiOperation{{
+				Method:   http.MethodGet,
+				Summary:  "A single game",
+				Params:   []apiParam{{Name: "id", In: "path", Description: "Game id"}},
+				Response: apiGame{},
+				Status:   http.StatusOK,
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}},
+		},
+		{
+			Pattern: "/api/v1/h2h",
+			Handler: a.handleAPIH2H,
+			Operations: []apiOperation{{
+				Method:  http.MethodGet,
+				Summary: "Head-to-head record of two players in their shared games",
+				Params: []apiParam{
+					{Name: "player1", In: "query", Description: "Player id"},
+					{Name: "player2", In: "query", Description: "Player id"},
+				},
+				Response: apiH2H{},
+				Status:   http.StatusOK,
+				Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+			}},
+		},
+	}
+}
+
+func (a *App) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !apiGet(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, openAPISpec(a.apiRoutes()))
+}
+
+func (a *App) handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	http.ServeFileFS(w, r, staticContent, "api.html")
+}
+
+// openAPISpec builds an OpenAPI 3 document describing routes.
+func openAPISpec(routes []apiRoute) map[string]any {
+	schemas := schemaGenerator{schemas: make(map[string]any)}
+	errorSchema := schemas.schemaFor(reflect.TypeOf(apiError{}))
+
+	paths := make(map[string]any)
+	for _, route := range routes {
+		item := make(map[string]any)
+		for _, op := range route.Operations {
+			operation := map[string]any{
+				"summary": op.Summary,
+			}
+
+			if len(op.Params) > 0 {
+				var params []any
+				for _, p := range op.Params {
+					params = append(params, map[string]any{
+						"name":        p.Name,
+						"in":          p.In,
+						"description": p.Description,
+						"required":    true,
+						"schema":      map[string]any{"type": "integer"},
+					})
+				}
+				operation["parameters"] = params
+			}
+
+			if op.Request != nil {
+				operation["requestBody"] = map[string]any{
+					"required": true,
+					"content": map[string]any{
+						"application/json": map[string]any{
+							"schema": schemas.schemaFor(reflect.TypeOf(op.Request)),
+						},
+					},
+				}
+			}
+
+			success := map[string]any{"description": http.StatusText(op.Status)}
+			if op.Response != nil {
+				success["content"] = map[string]any{
+					"application/json": map[string]any{
+						"schema": schemas.schemaFor(reflect.TypeOf(op.Response)),
+					},
+				}
+			}
+			responses := map[string]any{strconv.Itoa(op.Status): success}
+			for _, status := range append(op.Errors, http.StatusInternalServerError) {
+				responses[strconv.Itoa(status)] = map[string]any{
+					"description": http.StatusText(status),
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": errorSchema},
+					},
+				}
+			}
+			operation["responses"] = responses
+
+			if op.Auth {
+				operation["security"] = []any{map[string]any{"basicAuth": []any{}}}
+			}
+			item[strings.ToLower(op.Method)] = operation
+		}
+		paths[route.Pattern] = item
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Hest API",
+			"version": versioninfo.Short(),
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]any{
+				"basicAuth": map[string]any{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+}
+
+// schemaGenerator derives JSON schemas from Go types through their json tags.
+// Named structs become components referenced by name without the api prefix,
+// anonymous structs are inlined. Fields are required unless they are
+// omitempty or tagged api:"optional".
+type schemaGenerator struct {
+	schemas map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct && t.Name() == "":
+		return g.objectSchema(t)
+	case t.Kind() == reflect.Struct:
+		name := strings.TrimPrefix(t.Name(), "api")
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name first in case the type refers to itself
+			g.schemas[name] = nil
+			g.schemas[name] = g.objectSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (g schemaGenerator) objectSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	g.addFields(t, properties, &required)
+	sort.Strings(required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the fields of t to properties, flattening embedded structs
+// the same way encoding/json does.
+func (g schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			g.addFields(f.Type, properties, required)
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Tag.Get("api") != "optional" {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "hest.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return newApp(store)
+}
+
+// TestAPIMatchesSpec drives every /api/v1 route through the mux and checks
+// the requests and responses against the OpenAPI document, so the docs can't
+// drift from the handlers.
+func TestAPIMatchesSpec(t *testing.T) {
+	t.Setenv("HEST_PASSWORD", "secret")
+	a := newTestApp(t)
+	mux := a.routes().(*http.ServeMux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/openapi.json: status %d", rec.Code)
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	paths, _ := spec["paths"].(map[string]any)
+
+	for _, route := range a.apiRoutes() {
+		if _, ok := paths[route.Pattern]; !ok {
+			t.Errorf("route %s is missing from the spec paths", route.Pattern)
+		}
+	}
+
+	// The cases run in order, the first ones create the data the rest read
+	tests := []struct {
+		method string
+		target string
+		body   string
+		auth   bool
+		status int
+		// malformed marks bodies that aren't meant to match the request schema
+		malformed bool
+	}{
+		{method: "POST", target: "/api/v1/players", body: `{"name": "Anna"}`, auth: true, status: 201},
+		{method: "POST", target: "/api/v1/players", body: `{"name": "Bo"}`, auth: true, status: 201},
+		{method: "POST", target: "/api/v1/players", body: `{"name": "Carl"}`, auth: true, status: 201},
+		{method: "POST", target: "/api/v1/players", body: `{"name": "Dora"}`, auth: true, status: 201},
+		{method: "POST", target: "/api/v1/players", body: `{"name": "Anna"}`, auth: true, status: 409},
+		{method: "POST", target: "/api/v1/players", body: `{"name": " "}`, auth: true, status: 422},
+		{method: "POST", target: "/api/v1/players", body: `{"nme": "Emil"}`, auth: true, status: 400, malformed: true},
+		{method: "POST", target: "/api/v1/players", body: `{"name": "Emil"}`, status: 401},
+		{method: "POST", target: "/api/v1/games", body: `{"participants": [1, 2, 3], "winner_id": 1, "second_id": 2}`, auth: true, status: 201},
+		{method: "POST", target: "/api/v1/games", body: `{"played_at": "2024-05-01", "participants": [1, 2], "winner_id": 2, "second_id": 1, "session_id": 3}`, auth: true, status: 201},
+		{method: "POST", target: "/api/v1/games", body: `{"participants": [1], "winner_id": 1, "second_id": 1}`, auth: true, status: 422},
+		{method: "POST", target: "/api/v1/games", body: `{"participants": [1, 2]`, auth: true, status: 400, malformed: true},
+		{method: "POST", target: "/api/v1/games", body: `{"participants": [1, 2], "winner_id": 1, "second_id": 2}`, status: 401},
+		{method: "GET", target: "/api/v1/leaderboard", status: 200},
+		{method: "GET", target: "/api/v1/players", status: 200},
+		{method: "GET", target: "/api/v1/players/1", status: 200},
+		{method: "GET", target: "/api/v1/players/4", status: 200},
+		{method: "GET", target: "/api/v1/players/x", status: 400},
+		{method: "GET", target: "/api/v1/players/99", status: 404},
+		{method: "GET", target: "/api/v1/games", status: 200},
+		{method: "GET", target: "/api/v1/games/2", status: 200},
+		{method: "GET", target: "/api/v1/games/x", status: 400},
+		{method: "GET", target: "/api/v1/games/99", status: 404},
+		{method: "GET", target: "/api/v1/h2h?player1=1&player2=2", status: 200},
+		{method: "GET", target: "/api/v1/h2h?player1=1", status: 400},
+		{method: "GET", target: "/api/v1/h2h?player1=1&player2=99", status: 404},
+	}
+
+	covered := make(map[string]bool)
+	for _, tt := range tests {
+		name := tt.method + " " + tt.target
+		req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if tt.auth {
+			req.SetBasicAuth("test", "secret")
+		}
+
+		_, pattern := mux.Handler(req)
+		operation, ok := lookup(paths, pattern, strings.ToLower(tt.method)).(map[string]any)
+		if !ok {
+			t.Errorf("%s: no operation for %s in the spec", name, pattern)
+			continue
+		}
+
+		if tt.body != "" && !tt.malformed {
+			schema := lookup(operation, "requestBody", "content", "application/json", "schema")
+			if schema == nil {
+				t.Errorf("%s: request body is not documented", name)
+			}
+			var body any
+			if err := json.Unmarshal([]byte(tt.body), &body); err != nil {
+				t.Fatalf("%s: test body: %v", name, err)
+			}
+			for _, msg := range validateSchema(spec, schema, body, "request") {
+				t.Errorf("%s: %s", name, msg)
+			}
+		}
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", name, rec.Code, tt.status, rec.Body)
+			continue
+		}
+
+		response, ok := lookup(operation, "responses", strconv.Itoa(rec.Code)).(map[string]any)
+		if !ok {
+			t.Errorf("%s: status %d is not documented", name, rec.Code)
+			continue
+		}
+		covered[tt.method+" "+pattern+" "+strconv.Itoa(rec.Code)] = true
+
+		schema := lookup(response, "content", "application/json", "schema")
+		if schema == nil {
+			if rec.Body.Len() > 0 {
+				t.Errorf("%s: undocumented response body: %s", name, rec.Body)
+			}
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("%s: content type %q, want application/json", name, ct)
+		}
+		var body any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s: decode response: %v", name, err)
+			continue
+		}
+		for _, msg := range validateSchema(spec, schema, body, "response") {
+			t.Errorf("%s: %s", name, msg)
+		}
+	}
+
+	// Every documented operation must have been exercised with its success
+	// status, so new routes can't slip past this test
+	for _, route := range a.apiRoutes() {
+		for _, op := range route.Operations {
+			key := op.Method + " " + route.Pattern + " " + strconv.Itoa(op.Status)
+			if !covered[key] {
+				t.Errorf("no test case for %s", key)
+			}
+		}
+	}
+}
+
+func TestOpenAPISpecWithoutBodies(t *testing.T) {
+	routes := []apiRoute{{
+		Pattern: "/api/v1/things/{id}",
+		Operations: []apiOperation{{
+			Method: http.MethodDelete,
+			Status: http.StatusNoContent,
+		}, {
+			Method:   http.MethodGet,
+			Response: struct{ Name string }{},
+			Status:   http.StatusOK,
+		}},
+	}}
+
+	raw, err := json.Marshal(openAPISpec(routes))
+	if err != nil {
+		t.Fatalf("encode spec: %v", err)
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+
+	del := lookup(spec, "paths", "/api/v1/things/{id}", "delete").(map[string]any)
+	if _, ok := del["requestBody"]; ok {
+		t.Error("delete has a request body")
+	}
+	if content := lookup(del, "responses", "204", "content"); content != nil {
+		t.Errorf("204 response has content %v", content)
+	}
+
+	schema := lookup(spec, "paths", "/api/v1/things/{id}", "get", "responses", "200", "content", "application/json", "schema")
+	if msgs := validateSchema(spec, schema, map[string]any{"Name": "x"}, "response"); len(msgs) > 0 {
+		t.Errorf("anonymous struct schema: %v", msgs)
+	}
+	if schemas := lookup(spec, "components", "schemas").(map[string]any); schemas[""] != nil {
+		t.Error("anonymous struct registered as a component without a name")
+	}
+}
+
+// lookup walks nested JSON objects by key and returns nil if a key is missing.
+func lookup(v any, keys ...string) any {
+	for _, key := range keys {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// validateSchema checks value against the subset of JSON schema the spec uses
+// and returns a message per mismatch. Objects may not have properties the
+// schema doesn't list.
+func validateSchema(spec map[string]any, schema any, value any, path string) []string {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s: invalid schema %v", path, schema)}
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		keys := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+		return validateSchema(spec, lookup(spec, keys...), value, path)
+	}
+
+	var msgs []string
+	mismatch := func(want string) []string {
+		return append(msgs, fmt.Sprintf("%s: got %v, want %s", path, value, want))
+	}
+	switch s["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return mismatch("object")
+		}
+		properties, _ := s["properties"].(map[string]any)
+		required, _ := s["required"].([]any)
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				msgs = append(msgs, fmt.Sprintf("%s: missing required property %s", path, name))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			property, ok := properties[key]
+			if !ok {
+				msgs = append(msgs, fmt.Sprintf("%s: undocumented property %s", path, key))
+				continue
+			}
+			msgs = append(msgs, validateSchema(spec, property, obj[key], path+"."+key)...)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch("array")
+		}
+		for i, item := range items {
+			msgs = append(msgs, validateSchema(spec, s["items"], item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return mismatch("string")
+		}
+		if s["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return mismatch("date-time")
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return mismatch("integer")
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch("number")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch("boolean")
+		}
+	default:
+		msgs = append(msgs, fmt.Sprintf("%s: unsupported schema %v", path, s))
+	}
+	return msgs
+}
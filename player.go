@@ -2,10 +2,15 @@ package main
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/martinohansen/hest/internal/db"
 )
 
+// tableMatesLimit is the number of table-mates shown on the player page.
+const tableMatesLimit = 5
+
 type PlayerGameHistoryEntry db.PlayerGameHistoryEntry
 type PlayerRankHistoryEntry db.PlayerRankHistoryEntry
 
@@ -16,10 +21,17 @@ type playerDetailView struct {
 	GameHistory  []PlayerGameHistoryEntry
 	RankHistory  []PlayerRankHistoryEntry
 	Games        []Game
+	ShowingLast  int
 	HasGames     bool
 	TotalPlayers int
 	TotalGames   int
 	Rank         int
+	Activity     db.PlayerActivity
+	Weekday      string
+	Heatmap      heatmap
+	TableMates   []db.TableMate
+	FieldSizes   []db.FieldSizeStats
+	DaysAtTop    int
 }
 
 func newPlayerDetailView(player Player, rank int) playerDetailView {
@@ -48,6 +60,38 @@ func (p playerDetailView) withGames(games []Game) playerDetailView {
 	return p
 }
 
+func (p playerDetailView) withActivity(activity db.PlayerActivity, days []db.ActivityDay) playerDetailView {
+	p.Activity = activity
+	p.Weekday = weekdayNames[activity.BusiestWeekday]
+	p.Heatmap = newHeatmap(days, time.Now())
+	return p
+}
+
+// withLast limits the game list to the n most recent games. TotalGames keeps
+// counting all games so the numbering matches the full list.
+func (p playerDetailView) withLast(n int) playerDetailView {
+	if n > 0 && n < len(p.Games) {
+		p.Games = p.Games[:n]
+		p.ShowingLast = n
+	}
+	return p
+}
+
+func (p playerDetailView) withTableMates(mates []db.TableMate) playerDetailView {
+	p.TableMates = mates
+	return p
+}
+
+func (p playerDetailView) withFieldSizes(stats []db.FieldSizeStats) playerDetailView {
+	p.FieldSizes = stats
+	return p
+}
+
+func (p playerDetailView) withLeadership(reigns []db.Reign) playerDetailView {
+	p.DaysAtTop = db.DaysAtTop(reigns)[p.Player.ID]
+	return p
+}
+
 func (p playerDetailView) withTotalPlayers(total int) playerDetailView {
 	p.TotalPlayers = total
 	return p
@@ -71,6 +115,15 @@ func (a *App) handlePlayerDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var last int
+	if lastStr := r.URL.Query().Get("last"); lastStr != "" {
+		last, err = strconv.Atoi(lastStr)
+		if err != nil || last < 0 {
+			http.Error(w, "invalid last", http.StatusBadRequest)
+			return
+		}
+	}
+
 	players, err := a.Leaderboard()
 	if err != nil {
 		http.Error(w, "failed to load players", http.StatusInternalServerError)
@@ -115,7 +168,7 @@ func (a *App) handlePlayerDetail(w http.ResponseWriter, r *http.Request) {
 		rankHistory[i] = PlayerRankHistoryEntry(h)
 	}
 
-	gamesDB, err := a.store.PlayerGames(playerID)
+	gamesDB, err := a.store.PlayerGames(playerID, 0)
 	if err != nil {
 		http.Error(w, "failed to load player games", http.StatusInternalServerError)
 		return
@@ -126,11 +179,46 @@ func (a *App) handlePlayerDetail(w http.ResponseWriter, r *http.Request) {
 		games[i] = Game(g)
 	}
 
+	activity, err := a.store.PlayerActivity(playerID)
+	if err != nil {
+		http.Error(w, "failed to load player activity", http.StatusInternalServerError)
+		return
+	}
+
+	activityDays, err := a.store.PlayerActivityDays(playerID)
+	if err != nil {
+		http.Error(w, "failed to load player activity", http.StatusInternalServerError)
+		return
+	}
+
+	tableMates, err := a.store.TableMates(playerID, tableMatesLimit)
+	if err != nil {
+		http.Error(w, "failed to load table-mates", http.StatusInternalServerError)
+		return
+	}
+
+	fieldSizes, err := a.store.PlayerFieldSizeStats(playerID)
+	if err != nil {
+		http.Error(w, "failed to load field size stats", http.StatusInternalServerError)
+		return
+	}
+
+	reigns, err := a.store.LeadershipHistory(time.Now())
+	if err != nil {
+		http.Error(w, "failed to load leadership history", http.StatusInternalServerError)
+		return
+	}
+
 	view := newPlayerDetailView(player, rank).
 		withGameHistory(history).
 		withRankHistory(rankHistory).
 		withGames(games).
+		withLast(last).
+		withActivity(activity, activityDays).
+		withTableMates(tableMates).
+		withFieldSizes(fieldSizes).
+		withLeadership(reigns).
 		withTotalPlayers(len(players))
 
-	renderTemplate(w, "layout", view, "templates/layout.html", "templates/player.html")
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/player.html", "templates/heatmap.html")
 }
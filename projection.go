@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// projectionRuns is the number of simulated seasons behind a projection.
+const projectionRuns = 2000
+
+// season returns the season configured with HEST_SEASON_START and
+// HEST_SEASON_END, and whether it has an end date. Without a start the season
+// covers every game up to the end.
+func season() (db.DateRange, bool) {
+	end, ok := seasonDate("HEST_SEASON_END")
+	if !ok {
+		return db.DateRange{}, false
+	}
+	start, _ := seasonDate("HEST_SEASON_START")
+	if !start.IsZero() && start.After(end) {
+		slog.Warn("HEST_SEASON_START is after HEST_SEASON_END", "start", start.Format(dateLayout), "end", end.Format(dateLayout))
+		return db.DateRange{}, false
+	}
+	return db.DateRange{From: start, To: end}, true
+}
+
+// seasonDate parses the date in the environment variable name, and reports
+// whether one is set.
+func seasonDate(name string) (time.Time, bool) {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		slog.Warn("invalid "+name, "value", raw, "error", err)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// projectionRand returns a random source seeded by the date, so a projection
+// only changes during the day when new games are added.
+func projectionRand(now time.Time) *rand.Rand {
+	seed := uint64(now.Year()*10000 + int(now.Month())*100 + now.Day())
+	return rand.New(rand.NewPCG(seed, seed))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+type reviewView struct {
+	Path   string
+	Title  string
+	Review db.YearReview
+}
+
+func newReviewView(review db.YearReview) reviewView {
+	return reviewView{
+		Path:   "/player",
+		Title:  fmt.Sprintf("%s %d", review.Player.Name, review.Year),
+		Review: review,
+	}
+}
+
+func (a *App) handleYearReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	playerID, err := parsePlayer(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "invalid player id", http.StatusBadRequest)
+		return
+	}
+
+	// Redirect to the current year so the shared URL stays the same
+	yearStr := r.URL.Query().Get("year")
+	if yearStr == "" {
+		url := fmt.Sprintf("/player/year?id=%d&year=%d", playerID, time.Now().Year())
+		http.Redirect(w, r, url, http.StatusFound)
+		return
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 1 || year > 9999 {
+		http.Error(w, "invalid year", http.StatusBadRequest)
+		return
+	}
+
+	review, err := a.store.PlayerYearReview(playerID, year)
+	if errors.Is(err, db.ErrNotFound) {
+		http.Error(w, "player not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to load year review", http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, "layout", newReviewView(review), "templates/layout.html", "templates/review.html")
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+type sessionsView struct {
+	Path     string
+	Title    string
+	Sessions []db.Session
+}
+
+func newSessionsView(sessions []db.Session) sessionsView {
+	return sessionsView{
+		Path:     "/sessions",
+		Title:    "Aftener",
+		Sessions: sessions,
+	}
+}
+
+func (a *App) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sessions, err := a.store.ListSessions()
+	if err != nil {
+		http.Error(w, "failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+
+	renderTemplate(w, "layout", newSessionsView(sessions), "templates/layout.html", "templates/sessions.html")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// busiestDaysLimit is the number of days listed as the busiest.
+const busiestDaysLimit = 5
+
+type statsView struct {
+	Path             string
+	Title            string
+	Totals           db.GroupTotals
+	Months           []db.MonthStats
+	WinShares        []db.WinShare
+	EffectiveWinners float64
+	BusiestDays      []db.ActivityDay
+}
+
+func newStatsView() statsView {
+	return statsView{
+		Path:  "/stats",
+		Title: "Statistik",
+	}
+}
+
+func (s statsView) withTotals(totals db.GroupTotals) statsView {
+	s.Totals = totals
+	return s
+}
+
+func (s statsView) withMonths(months []db.MonthStats) statsView {
+	s.Months = months
+	return s
+}
+
+func (s statsView) withWinShares(shares []db.WinShare) statsView {
+	s.WinShares = shares
+	s.EffectiveWinners = db.EffectiveWinners(shares)
+	return s
+}
+
+func (s statsView) withBusiestDays(days []db.ActivityDay) statsView {
+	s.BusiestDays = days
+	return s
+}
+
+func (a *App) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	totals, err := a.store.GroupTotals()
+	if err != nil {
+		http.Error(w, "failed to load totals", http.StatusInternalServerError)
+		return
+	}
+
+	months, err := a.store.MonthlyStats()
+	if err != nil {
+		http.Error(w, "failed to load monthly stats", http.StatusInternalServerError)
+		return
+	}
+
+	shares, err := a.store.WinDistribution()
+	if err != nil {
+		http.Error(w, "failed to load win distribution", http.StatusInternalServerError)
+		return
+	}
+
+	days, err := a.store.BusiestDays(busiestDaysLimit)
+	if err != nil {
+		http.Error(w, "failed to load busiest days", http.StatusInternalServerError)
+		return
+	}
+
+	view := newStatsView().
+		withTotals(totals).
+		withMonths(months).
+		withWinShares(shares).
+		withBusiestDays(days)
+
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/stats.html")
+}
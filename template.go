@@ -1,11 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"html/template"
 	"net/http"
 	"path/filepath"
 
 	"github.com/carlmjohnson/versioninfo"
+	"github.com/martinohansen/hest/internal/db"
 )
 
 func renderTemplate(w http.ResponseWriter, tplName string, data any, files ...string) {
@@ -19,9 +21,11 @@ func render(tplName string, w http.ResponseWriter, data any, files ...string) er
 		files[i] = filepath.Clean(f)
 	}
 	funcs := template.FuncMap{
-		"add":      func(a, b int) int { return a + b },
-		"subtract": func(a, b int) int { return a - b },
-		"version":  func() string { return versioninfo.Short() },
+		"add":          func(a, b int) int { return a + b },
+		"subtract":     func(a, b int) int { return a - b },
+		"percent":      func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+		"version":      func() string { return versioninfo.Short() },
+		"maxFieldSize": func() int { return db.MaxFieldSize },
 	}
 	tpl, err := template.New(filepath.Base(files[0])).Funcs(funcs).ParseFS(templateFS, files...)
 	if err != nil {
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/martinohansen/hest/internal/db"
+)
+
+// whatIfRow compares a player's standing under an alternative scoring with
+// the real leaderboard. Change is positive when the player moves up.
+type whatIfRow struct {
+	Player     db.Player
+	Rank       int
+	RealRank   int
+	RealPoints int
+	Change     int
+}
+
+type whatIfView struct {
+	Path   string
+	Title  string
+	Scheme db.ScoringScheme
+	Rows   []whatIfRow
+	Error  string
+}
+
+func newWhatIfView(scheme db.ScoringScheme) whatIfView {
+	return whatIfView{
+		Path:   "/whatif",
+		Title:  "Hvad nu hvis",
+		Scheme: scheme,
+	}
+}
+
+func (v whatIfView) withError(msg string) whatIfView {
+	v.Error = msg
+	return v
+}
+
+// withLeaderboards lines up the rescored leaderboard with the real one.
+func (v whatIfView) withLeaderboards(current, rescored []db.Player) whatIfView {
+	realRanks := make(map[int]int, len(current))
+	realPoints := make(map[int]int, len(current))
+	for i, p := range current {
+		realRanks[p.ID] = i + 1
+		realPoints[p.ID] = p.Points
+	}
+
+	v.Rows = make([]whatIfRow, len(rescored))
+	for i, p := range rescored {
+		v.Rows[i] = whatIfRow{
+			Player:     p,
+			Rank:       i + 1,
+			RealRank:   realRanks[p.ID],
+			RealPoints: realPoints[p.ID],
+			Change:     realRanks[p.ID] - (i + 1),
+		}
+	}
+	return v
+}
+
+// parseScheme reads a scoring scheme from the query, falling back to the
+// default points for missing values. An invalid value gives the default
+// scheme and a message.
+func parseScheme(r *http.Request) (db.ScoringScheme, string) {
+	scheme := db.DefaultScoring
+	fields := []struct {
+		name  string
+		value *int
+	}{
+		{"win", &scheme.Win},
+		{"second", &scheme.Second},
+		{"played", &scheme.Played},
+	}
+	for _, f := range fields {
+		raw := strings.TrimSpace(r.URL.Query().Get(f.name))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 || n > 100 {
+			return db.DefaultScoring, "Point skal være hele tal fra 0 til 100."
+		}
+		*f.value = n
+	}
+	return scheme, ""
+}
+
+func (a *App) handleWhatIf(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	current, err := a.store.ListPlayersByPoints()
+	if err != nil {
+		http.Error(w, "loading leaderboard", http.StatusInternalServerError)
+		return
+	}
+
+	scheme, msg := parseScheme(r)
+	view := newWhatIfView(scheme)
+	if msg != "" {
+		view = view.withError(msg)
+	}
+
+	rescored := db.Rescore(current, scheme)
+	view = view.withLeaderboards(current, rescored)
+
+	renderTemplate(w, "layout", view, "templates/layout.html", "templates/whatif.html")
+}